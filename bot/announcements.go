@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/metrics"
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+// maxReportedFailures is how many of the guilds an announcement failed on are
+// listed when the broadcast is done, the rest are only on the database.
+const maxReportedFailures = 5
+
+// handleMaintAnnounce posts the changelog of a version to every guild that
+// opted in to the announcements, across all the shards. Guilds that already
+// got the version are skipped, so running it again retries the ones that
+// failed.
+//
+// i.e. maint announce v1.9.0
+func (b *Bot) handleMaintAnnounce(env *commandEnvironment) error {
+	if env.session == nil {
+		return botError{
+			title:   "Not Available",
+			details: "Announcements can only be sent while connected to Discord",
+		}
+	}
+
+	version := metrics.Version
+	if len(env.args) > 1 && env.args[1] != "" {
+		version = env.args[1]
+	}
+	entry, err := b.repository.Changelog(version)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Changelog Not Found",
+				details: fmt.Sprintf("There is no changelog entry for version %q", version),
+			}
+		}
+		return err
+	}
+
+	if !atomic.CompareAndSwapInt32(&b.announcing, 0, 1) {
+		return botError{
+			title:   "Announcement In Progress",
+			details: "Wait for the current announcement to finish before sending another one",
+		}
+	}
+
+	pending, deliveries, err := b.pendingAnnouncements(version)
+	if err != nil {
+		atomic.StoreInt32(&b.announcing, 0)
+		return err
+	}
+	if len(pending) == 0 {
+		atomic.StoreInt32(&b.announcing, 0)
+		return botError{
+			title:   "Nothing To Announce",
+			details: fmt.Sprintf("Every server with an announcements channel already got %s", version),
+		}
+	}
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		defer atomic.StoreInt32(&b.announcing, 0)
+		sent, failed := b.broadcastAnnouncement(b.done, entry, pending, deliveries)
+		b.reportAnnouncement(env.session, env.channelID, entry, len(pending), sent, failed)
+	}()
+
+	embed := b.newEmbed()
+	embed.Title = "Announcement Started"
+	embed.Description = fmt.Sprintf(
+		"Announcing %s to %d servers, which takes about %s. The results will be posted here once it is done.",
+		version, len(pending), (time.Duration(len(pending)) * b.config.Announcements.Interval).Round(time.Second),
+	)
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+// pendingAnnouncements returns the guilds that opted in to the announcements
+// and did not get the version yet, along with the existing deliveries of the
+// version keyed by guild ID.
+func (b *Bot) pendingAnnouncements(version string) (
+	[]*repository.GuildSettings,
+	map[string]*repository.AnnouncementDelivery,
+	error,
+) {
+	guilds, err := b.repository.GuildsWithAnnouncements()
+	if err != nil {
+		return nil, nil, err
+	}
+	deliveries, err := b.repository.AnnouncementDeliveries(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending := make([]*repository.GuildSettings, 0, len(guilds))
+	for _, settings := range guilds {
+		if delivery, ok := deliveries[settings.DiscordID]; ok && delivery.DeliveredAt != nil {
+			continue
+		}
+		pending = append(pending, settings)
+	}
+	return pending, deliveries, nil
+}
+
+// broadcastAnnouncement sends the announcement to each guild, one at a time,
+// and records the outcome of each of them, until the done channel is closed.
+// It returns how many were sent, and the deliveries that failed.
+func (b *Bot) broadcastAnnouncement(
+	done <-chan struct{},
+	entry *repository.ChangelogEntry,
+	guilds []*repository.GuildSettings,
+	deliveries map[string]*repository.AnnouncementDelivery,
+) (int, []*repository.AnnouncementDelivery) {
+	var tick <-chan time.Time
+	if b.config.Announcements.Interval > 0 {
+		ticker := time.NewTicker(b.config.Announcements.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	sent := 0
+	failed := make([]*repository.AnnouncementDelivery, 0)
+	for i, settings := range guilds {
+		if i > 0 && tick != nil {
+			select {
+			case <-done:
+				return sent, failed
+			case <-tick:
+			}
+		}
+		select {
+		case <-done:
+			return sent, failed
+		default:
+		}
+
+		delivery, ok := deliveries[settings.DiscordID]
+		if !ok {
+			delivery = &repository.AnnouncementDelivery{
+				GuildID: settings.DiscordID,
+				Version: entry.Version,
+			}
+		}
+		delivery.ChannelID = settings.AnnouncementsChannelID
+
+		s := b.sessionForGuild(settings.DiscordID)
+		msg, err := s.ChannelMessageSendEmbed(settings.AnnouncementsChannelID, b.announcementEmbed(entry, settings))
+		if err != nil {
+			delivery.Error = err.Error()
+			failed = append(failed, delivery)
+			zap.L().Warn(
+				"failed to send announcement",
+				zap.Error(err),
+				zap.String("guild_id", settings.DiscordID),
+				zap.String("version", entry.Version),
+			)
+		} else {
+			sent++
+			now := time.Now()
+			delivery.MessageID = msg.ID
+			delivery.Error = ""
+			delivery.DeliveredAt = &now
+		}
+
+		if err := b.repository.SaveAnnouncementDelivery(delivery); err != nil {
+			zap.L().Error(
+				"failed to save announcement delivery",
+				zap.Error(err),
+				zap.String("guild_id", settings.DiscordID),
+				zap.String("version", entry.Version),
+			)
+		}
+	}
+	return sent, failed
+}
+
+// reportAnnouncement lets the maintainer know how the broadcast went, on the
+// channel it was started from.
+func (b *Bot) reportAnnouncement(
+	s *discordgo.Session,
+	channelID string,
+	entry *repository.ChangelogEntry,
+	total, sent int,
+	failed []*repository.AnnouncementDelivery,
+) {
+	embed := b.newEmbed()
+	embed.Title = "Announcement Finished"
+	embed.Description = fmt.Sprintf("%s was sent to %d of %d servers.", entry.Version, sent, total)
+	embed.Color = 0x00FF00
+	if skipped := total - sent - len(failed); skipped > 0 {
+		embed.Title = "Announcement Stopped"
+		embed.Description += fmt.Sprintf(
+			" The bot is shutting down, so %d were left out, run it again to send them.",
+			skipped,
+		)
+		embed.Color = b.config.Bot.WarningEmbedColor
+	}
+	if len(failed) > 0 {
+		lines := make([]string, 0, maxReportedFailures+1)
+		for i, delivery := range failed {
+			if i == maxReportedFailures {
+				lines = append(lines, fmt.Sprintf("and %d more", len(failed)-maxReportedFailures))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("`%s`: %s", delivery.GuildID, delivery.Error))
+		}
+		embed.Color = b.config.Bot.WarningEmbedColor
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   fmt.Sprintf("Failed (%d)", len(failed)),
+				Value:  strings.Join(lines, "\n"),
+				Inline: false,
+			},
+		}
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed); err != nil {
+		zap.L().Warn("failed to report announcement", zap.Error(err), zap.String("channel_id", channelID))
+	}
+}
+
+func (b *Bot) announcementEmbed(entry *repository.ChangelogEntry, settings *repository.GuildSettings) *discordgo.MessageEmbed {
+	changes := make([]string, len(entry.Changes))
+	for i, change := range entry.Changes {
+		changes[i] = "• " + change
+	}
+
+	embed := b.newEmbed()
+	embed.Title = fmt.Sprintf("What's New In %s %s", b.config.Bot.Name, entry.Version)
+	embed.Description = strings.TrimSpace(entry.Summary + "\n\n" + strings.Join(changes, "\n"))
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name: "Announcements",
+			Value: fmt.Sprintf(
+				"Server admins can stop these with `%ssettings announcements reset`",
+				b.mainPrefix(settings),
+			),
+			Inline: false,
+		},
+	}
+	return embed
+}
@@ -10,11 +10,7 @@ import (
 
 // handleBallCmd handles the ball command, sends back a gif
 // animation of the ball being used, and basic information.
-func (b *Bot) handleBallCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleBallCmd(env *commandEnvironment) error {
 	if len(env.args) == 0 {
 		return botError{
 			title:   "Validation Error",
@@ -23,12 +19,15 @@ func (b *Bot) handleBallCmd(
 	}
 	pkmArgs := parsePokemonCommand(env.command, env.args)
 
-	ball, err := b.repository.Ball(pkmArgs.ball)
+	// if the ball name was not recognized, it may be misspelled so try to
+	// find it with everything we got.
+	ballName := pkmArgs.ball
+	if ballName == "" {
+		ballName = strings.Join(env.args, " ")
+	}
+	ball, err := b.findBall(ballName)
 	if err != nil {
-		return botError{
-			title:   "Pokéball not found",
-			details: fmt.Sprintf("Pokéball %s could not be found.", pkmArgs.ball),
-		}
+		return err
 	}
 
 	embed := b.newEmbed()
@@ -68,5 +67,5 @@ func (b *Bot) handleBallCmd(
 		ball.Conditions,
 	)
 
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
@@ -15,11 +15,7 @@ const (
 
 // handleCatchCmd handles the catch command, sends back a
 // detailed summary of catch rates for a given Pokémon & Ball.
-func (b *Bot) handleCatchCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleCatchCmd(env *commandEnvironment) error {
 	if len(env.args) == 0 {
 		return botError{
 			title:   "Validation Error",
@@ -36,17 +32,17 @@ func (b *Bot) handleCatchCmd(
 		pkmArgs.isShiny = strings.HasSuffix(env.args[0], "*") || strings.HasPrefix(env.args[0], "*")
 	}
 
-	pkm, err := b.repository.Pokemon(pkmArgs.name)
+	pkm, err := b.findPokemon(pkmArgs.name)
 	if err != nil {
-		return botError{
-			title:   "Not Found",
-			details: fmt.Sprintf("Pokemon %s was not found", pkmArgs.name),
-		}
+		return err
 	}
 
-	ball, err := b.repository.Ball(pkmArgs.ball)
-	if err != nil && err != repository.ErrBallDoesNotExist {
-		return err
+	// the ball is optional, so only look it up if we got one
+	var ball *repository.PokeBall
+	if pkmArgs.ball != "" {
+		if ball, err = b.findBall(pkmArgs.ball); err != nil {
+			return err
+		}
 	}
 
 	// now make sure that someone did not just send in a ball and no pokemon
@@ -63,7 +59,7 @@ func (b *Bot) handleCatchCmd(
 		if err != nil {
 			return err
 		}
-		return sendEmbed(s, m.ChannelID, embed)
+		return env.Reply(embed)
 	}
 
 	// If we got a ball, we are doing an specific check against a pokemon.
@@ -71,7 +67,7 @@ func (b *Bot) handleCatchCmd(
 	if err != nil {
 		return err
 	}
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
 
 func (b *Bot) getPokemonCatchRate(
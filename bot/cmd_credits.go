@@ -5,11 +5,7 @@ import (
 )
 
 // handleCreditsCmd handles the credits command
-func (b *Bot) handleCreditsCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleCreditsCmd(env *commandEnvironment) error {
 	embed := b.newEmbed()
 	embed.Title = "Rotom-B - Credits"
 	embed.URL = "https://github.com/caquillo07/rotom-b"
@@ -37,6 +33,5 @@ func (b *Bot) handleCreditsCmd(
 		},
 	}
 
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
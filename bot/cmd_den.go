@@ -9,11 +9,7 @@ import (
 	"github.com/caquillo07/rotom-bot/repository"
 )
 
-func (b *Bot) handleDenCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleDenCmd(env *commandEnvironment) error {
 	if len(env.args) == 0 {
 		return botError{
 			title:   "Validation Error",
@@ -28,7 +24,7 @@ func (b *Bot) handleDenCmd(
 		if err != nil {
 			return err
 		}
-		return sendEmbed(s, m.ChannelID, embed)
+		return env.Reply(embed)
 	}
 
 	// if the name and shininess were not parsed properly, lets assume it
@@ -43,7 +39,7 @@ func (b *Bot) handleDenCmd(
 		return err
 	}
 
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
 
 func (b *Bot) getDensFromPokemon(pkmnName, form string, isShiny bool) (*discordgo.MessageEmbed, error) {
@@ -54,13 +50,9 @@ func (b *Bot) getDensFromPokemon(pkmnName, form string, isShiny bool) (*discordg
 	if form == galarian {
 		pkmnName = form + " " + pkmnName
 	}
-	pkm, err := b.repository.Pokemon(pkmnName)
+	pkm, err := b.findPokemon(pkmnName)
 	if err != nil {
-		return nil, botError{
-			title: "Pokémon not found",
-			details: fmt.Sprintf("Pokémon %s could not be found.",
-				pkmnName),
-		}
+		return nil, err
 	}
 
 	if len(pkm.Dens.Shield) == 0 && len(pkm.Dens.Sword) == 0 {
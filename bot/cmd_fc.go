@@ -0,0 +1,291 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	fcSet        = "set"
+	fcDelete     = "delete"
+	fcVisibility = "visibility"
+	fcHide       = "hide"
+
+	fcFieldCode = "code"
+	fcFieldIGN  = "ign"
+	fcFieldGame = "game"
+
+	fcVisibilityGlobal = "global"
+	fcVisibilityServer = "server"
+
+	// ignMaxLength is the longest in game name Sword and Shield allows
+	ignMaxLength = 12
+)
+
+var (
+	friendCodeRegex = regexp.MustCompile(`^SW-\d{4}-\d{4}-\d{4}$`)
+	fcGames         = []string{"sword", "shield"}
+)
+
+// handleFriendCodeCmd handles the "fc" command, which stores and looks up
+// the users' friend codes, in game names and games.
+func (b *Bot) handleFriendCodeCmd(env *commandEnvironment) error {
+	if len(env.args) == 0 || env.args[0] == "" {
+		return b.handleFriendCodeShow(env, env.user.ID)
+	}
+
+	switch sub := strings.ToLower(env.args[0]); sub {
+	case fcSet:
+		return b.handleFriendCodeSet(env)
+	case fcVisibility:
+		return b.handleFriendCodeVisibility(env)
+	case fcHide:
+		return b.handleFriendCodeHide(env)
+	case fcDelete:
+		if err := b.repository.DeleteUserProfile(env.user.ID); err != nil {
+			if err == repository.ErrRecordNotFound {
+				return errNoFriendCode(env)
+			}
+			return err
+		}
+		return b.replyFriendCodeUpdated(env, "Your friend code, IGN and game were deleted")
+	default:
+		match := userIDRegex.FindStringSubmatch(env.args[0])
+		if len(match) != 3 {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("%q is not a valid user, mention them or use their ID", env.args[0]),
+			}
+		}
+		userID := match[1]
+		if userID == "" {
+			userID = match[2]
+		}
+		return b.handleFriendCodeShow(env, userID)
+	}
+}
+
+func (b *Bot) handleFriendCodeShow(env *commandEnvironment, userID string) error {
+	self := userID == env.user.ID
+	profile, err := b.repository.GetUserProfile(userID)
+	if err != nil && err != repository.ErrRecordNotFound {
+		return err
+	}
+
+	// profiles that are not shared here look the same as missing ones, so
+	// hidden users can't be told apart from users without a profile.
+	if profile == nil || !self && !profile.VisibleOn(env.guildID) {
+		if self {
+			return errNoFriendCode(env)
+		}
+		return botError{
+			title:   "Friend Code Not Found",
+			details: fmt.Sprintf("<@%s> has not shared a friend code on this server", userID),
+		}
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Friend Code", Value: valueOrNA(profile.FriendCode), Inline: true},
+		{Name: "IGN", Value: valueOrNA(profile.IGN), Inline: true},
+		{Name: "Game", Value: valueOrNA(strings.Title(profile.Game)), Inline: true},
+	}
+	if self {
+		visibility := "Only on the servers you used `fc set` on"
+		if profile.Global {
+			visibility = "Every server"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Visible On",
+			Value: visibility,
+		})
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Trainer Card"
+	embed.Description = fmt.Sprintf("<@%s>", userID)
+	embed.Fields = fields
+	return env.Reply(embed)
+}
+
+// handleFriendCodeSet updates one of the fields of the user's profile, and
+// shares it with the guild it was invoked on.
+//
+// i.e. fc set code SW-1234-5678-9012
+func (b *Bot) handleFriendCodeSet(env *commandEnvironment) error {
+	if len(env.args) < 3 {
+		return botError{
+			title: "Validation Error",
+			details: fmt.Sprintf(
+				"Field and value are required, i.e. `%sfc %s %s SW-1234-5678-9012`, field must be one of %s, %s or %s",
+				env.commandPrefix, fcSet, fcFieldCode, fcFieldCode, fcFieldIGN, fcFieldGame,
+			),
+		}
+	}
+
+	profile, err := b.getOrNewUserProfile(env.user.ID)
+	if err != nil {
+		return err
+	}
+
+	field, value := strings.ToLower(env.args[1]), strings.TrimSpace(strings.Join(env.args[2:], " "))
+	switch field {
+	case fcFieldCode:
+		code := strings.ToUpper(value)
+		if !friendCodeRegex.MatchString(code) {
+			return botError{
+				title:   "Validation Error",
+				details: "Friend codes must look like `SW-1234-5678-9012`",
+			}
+		}
+		profile.FriendCode = code
+	case fcFieldIGN:
+		if value == "" || utf8.RuneCountInString(value) > ignMaxLength {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("IGN is required, and can be up to %d characters long", ignMaxLength),
+			}
+		}
+		profile.IGN = value
+	case fcFieldGame:
+		game := strings.ToLower(value)
+		if !contains(fcGames, game) {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("Game must be one of %s", strings.Join(fcGames, " or ")),
+			}
+		}
+		profile.Game = game
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Field must be one of %s, %s or %s", fcFieldCode, fcFieldIGN, fcFieldGame),
+		}
+	}
+
+	// sharing the profile links it to the guild's data, so it is exported
+	// along with it, and unlinked when the guild's data is deleted.
+	if env.guildID != "" && !contains(profile.GuildIDs, env.guildID) {
+		profile.GuildIDs = append(profile.GuildIDs, env.guildID)
+	}
+	if err := b.repository.SaveUserProfile(profile); err != nil {
+		return err
+	}
+	return b.replyFriendCodeUpdated(env, fmt.Sprintf("Your %s was updated", field))
+}
+
+// handleFriendCodeVisibility makes the user's profile visible on every
+// guild, or only on the ones it was shared with.
+//
+// i.e. fc visibility global
+func (b *Bot) handleFriendCodeVisibility(env *commandEnvironment) error {
+	visibility := ""
+	if len(env.args) > 1 {
+		visibility = strings.ToLower(env.args[1])
+	}
+	if visibility != fcVisibilityGlobal && visibility != fcVisibilityServer {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Visibility must be one of %s or %s", fcVisibilityGlobal, fcVisibilityServer),
+		}
+	}
+
+	profile, err := b.repository.GetUserProfile(env.user.ID)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return errNoFriendCode(env)
+		}
+		return err
+	}
+
+	profile.Global = visibility == fcVisibilityGlobal
+	if err := b.repository.SaveUserProfile(profile); err != nil {
+		return err
+	}
+
+	if profile.Global {
+		return b.replyFriendCodeUpdated(env, "Your friend code is now visible on every server")
+	}
+	return b.replyFriendCodeUpdated(env, fmt.Sprintf(
+		"Your friend code is now only visible on the servers you used `%sfc %s` on",
+		env.commandPrefix, fcSet,
+	))
+}
+
+// handleFriendCodeHide stops sharing the user's profile with the guild it was
+// invoked on.
+func (b *Bot) handleFriendCodeHide(env *commandEnvironment) error {
+	if env.guildID == "" {
+		return botError{
+			title:   "Not Available In DMs",
+			details: "Use this command on the server you want to hide your friend code from",
+		}
+	}
+
+	profile, err := b.repository.GetUserProfile(env.user.ID)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return errNoFriendCode(env)
+		}
+		return err
+	}
+
+	guildIDs := make(pq.StringArray, 0, len(profile.GuildIDs))
+	for _, id := range profile.GuildIDs {
+		if id != env.guildID {
+			guildIDs = append(guildIDs, id)
+		}
+	}
+	profile.GuildIDs = guildIDs
+	if err := b.repository.SaveUserProfile(profile); err != nil {
+		return err
+	}
+
+	description := "Your friend code is no longer visible on this server"
+	if profile.Global {
+		description = fmt.Sprintf(
+			"Your friend code is still visible on every server, use `%sfc %s %s` to change that",
+			env.commandPrefix, fcVisibility, fcVisibilityServer,
+		)
+	}
+	return b.replyFriendCodeUpdated(env, description)
+}
+
+func (b *Bot) getOrNewUserProfile(userID string) (*repository.UserProfile, error) {
+	profile, err := b.repository.GetUserProfile(userID)
+	if err == repository.ErrRecordNotFound {
+		return &repository.UserProfile{UserID: userID}, nil
+	}
+	return profile, err
+}
+
+func (b *Bot) replyFriendCodeUpdated(env *commandEnvironment, description string) error {
+	embed := b.newEmbed()
+	embed.Title = "Update Successful"
+	embed.Description = description
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+func errNoFriendCode(env *commandEnvironment) error {
+	return botError{
+		title: "Friend Code Not Found",
+		details: fmt.Sprintf(
+			"You have not saved a friend code yet, use `%sfc %s %s SW-1234-5678-9012`",
+			env.commandPrefix, fcSet, fcFieldCode,
+		),
+	}
+}
+
+func valueOrNA(s string) string {
+	if s == "" {
+		return "N/A"
+	}
+	return s
+}
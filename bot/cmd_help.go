@@ -9,16 +9,12 @@ import (
 )
 
 // handleHelpCmd handles the "help" command
-func (b *Bot) handleHelpCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleHelpCmd(env *commandEnvironment) error {
 
 	// if we got a help request for a particular command,
 	// return the detailed help details for it instead.
 	if len(env.args) > 0 {
-		return b.handleCommandUsage(s, env, m)
+		return b.handleCommandUsage(env)
 	}
 
 	// Maps on Go do not guarantee key order, so before we fetch help text
@@ -33,6 +29,12 @@ func (b *Bot) handleHelpCmd(
 	commandFields := make([]*discordgo.MessageEmbedField, 0)
 	for _, name := range commandNames {
 		cmd := b.commands[name]
+		if cmd.botAdminOnly && !b.isMaintainer(env.user.ID) {
+			continue
+		}
+		if commandDisabled(env.guildSettings, name, cmd, env.channelID) {
+			continue
+		}
 		if cmd.helpText != "" {
 			commandFields = append(commandFields, &discordgo.MessageEmbedField{
 				Name:   cmd.usage(env.commandPrefix),
@@ -65,39 +67,40 @@ func (b *Bot) handleHelpCmd(
 		- _Catch Rates are calculated under Raid Specific Conditions: Levels 30-70, 1 HP, and no status modifiers._
 
 		Use "%shelp [command]" for more information about a command.
+		Forgot the prefix? Mention the bot instead, i.e. "@%s help".
 		`,
 		env.commandPrefix,
+		b.config.Bot.Name,
 	)
 
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
 
 // handleCommandUsage sends the embed message with help for a given command
-func (b *Bot) handleCommandUsage(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
-	command, ok := b.commands[env.args[0]]
-	if !ok {
-		_, err := s.ChannelMessageSendEmbed(
-			m.ChannelID,
-			b.newErrorEmbedf(
-				"Command Error",
-				`The command "%s" does not exist`,
-				env.args[0],
-			),
-		)
-		return err
+func (b *Bot) handleCommandUsage(env *commandEnvironment) error {
+	env.args[0] = strings.ToLower(env.args[0])
+	command, ok := b.resolveCommand(env.args[0], env.guildSettings)
+	if !ok || command.botAdminOnly && !b.isMaintainer(env.user.ID) ||
+		commandDisabled(env.guildSettings, env.args[0], command, env.channelID) {
+		return env.Reply(b.newErrorEmbedf(
+			"Command Error",
+			`The command "%s" does not exist`,
+			env.args[0],
+		))
 	}
 
 	aliases := make([]string, 0)
 	for key := range b.commands {
-		if b.commands[key].alias != "" && b.commands[key].alias == env.args[0] {
+		if b.commands[key].alias != "" && b.commands[key].alias == command.name &&
+			!env.guildSettings.DisabledCommands.IsDisabled(key, env.channelID) {
 			aliases = append(aliases, fmt.Sprintf("%s%s", env.commandPrefix, key))
 		}
 	}
+	for _, alias := range guildAliasesFor(env.guildSettings, command.name) {
+		if !env.guildSettings.DisabledCommands.IsDisabled(alias, env.channelID) {
+			aliases = append(aliases, fmt.Sprintf("%s%s", env.commandPrefix, alias))
+		}
+	}
 
 	fields := make([]*discordgo.MessageEmbedField, 0)
 	fields = append(fields, &discordgo.MessageEmbedField{
@@ -133,6 +136,5 @@ func (b *Bot) handleCommandUsage(
 	embed.Description = "**" + env.command + "**: " + command.helpText
 	embed.Fields = fields
 
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const hostClose = "close"
+
+// linkCodeRegex matches the 4 and 8 digit link codes, 8 digit codes can be
+// split in two like the game shows them.
+var linkCodeRegex = regexp.MustCompile(`^\d{4}$|^\d{8}$`)
+
+// handleHostCmd handles the "host" command, which opens a Max Raid lobby
+// challengers join by reacting to it.
+//
+// i.e. host toxtricity 1234 5678 gmax shiny
+func (b *Bot) handleHostCmd(env *commandEnvironment) error {
+	if env.guildID == "" || env.session == nil {
+		return botError{
+			title:   "Not Available In DMs",
+			details: "Raids are hosted on a server, use this command in one",
+		}
+	}
+	if len(env.args) == 0 || env.args[0] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Please enter a den number or a Pokémon name to host a raid for.",
+		}
+	}
+	if strings.ToLower(env.args[0]) == hostClose {
+		return b.handleHostClose(env)
+	}
+
+	raidChannel := env.guildSettings.RaidChannelID
+	if raidChannel != "" && raidChannel != env.channelID {
+		return botError{
+			title:   "Wrong Channel",
+			details: fmt.Sprintf("Raids on this server are hosted on <#%s>", raidChannel),
+		}
+	}
+
+	lobby, err := b.parseHostArgs(env.args)
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.repository.GetOpenRaidLobbyByHost(env.guildID, env.user.ID); err != repository.ErrRecordNotFound {
+		if err != nil {
+			return err
+		}
+		return alreadyHostingError(env)
+	}
+
+	// the message that invoked the command has the link code on it, try to
+	// remove it so only the challengers get it.
+	if lobby.Code != "" && env.messageID != "" {
+		_ = env.session.ChannelMessageDelete(env.channelID, env.messageID, discordgo.WithContext(env.ctx))
+	}
+
+	now := time.Now()
+	lobby.GuildID = env.guildID
+	lobby.ChannelID = env.channelID
+	lobby.HostID = env.user.ID
+	lobby.CreatedAt = now
+	lobby.ExpiresAt = now.Add(b.config.Raids.LobbyDuration)
+
+	msg, err := env.session.ChannelMessageSendEmbed(env.channelID, b.raidLobbyEmbed(lobby), discordgo.WithContext(env.ctx))
+	if err != nil {
+		return err
+	}
+	lobby.MessageID = msg.ID
+	if err := b.repository.CreateRaidLobby(lobby); err != nil {
+		// nothing tracks the message without its lobby, so it is removed
+		// before anyone reacts to it.
+		if delErr := env.session.ChannelMessageDelete(env.channelID, msg.ID); delErr != nil {
+			zap.L().Warn("failed to delete raid lobby message", zap.Error(delErr), zap.String("message_id", msg.ID))
+		}
+
+		// the same host may have opened another lobby since it was checked
+		if err == repository.ErrRecordExists {
+			return alreadyHostingError(env)
+		}
+		return err
+	}
+	b.lobbies.track(lobby.GuildID, lobby.MessageID)
+
+	for _, emoji := range []string{raidJoinEmoji, raidCloseEmoji} {
+		if err := env.session.MessageReactionAdd(env.channelID, msg.ID, emoji, discordgo.WithContext(env.ctx)); err != nil {
+			return err
+		}
+	}
+	b.notifyRaidSubscribers(env.session, env.guildSettings, env.channelID, msg.ID, env.user.ID, &repository.RaidSubscription{
+		Pokemon:    lobby.Pokemon,
+		Shiny:      lobby.Shiny,
+		Gigantamax: lobby.Gigantamax,
+	})
+
+	// the lobby message already answers commands sent as messages, slash
+	// commands still need an answer of their own.
+	if env.messageID != "" {
+		return nil
+	}
+	embed := b.newEmbed()
+	embed.Title = "Raid Opened"
+	embed.Description = fmt.Sprintf("Your raid for %s is open, challengers can join it above.", raidLobbySubject(lobby))
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+func alreadyHostingError(env *commandEnvironment) error {
+	return botError{
+		title:   "Already Hosting",
+		details: fmt.Sprintf("You are already hosting a raid, close it first with `%shost %s`", env.commandPrefix, hostClose),
+	}
+}
+
+func (b *Bot) handleHostClose(env *commandEnvironment) error {
+	lobby, err := b.repository.GetOpenRaidLobbyByHost(env.guildID, env.user.ID)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Not Hosting",
+				details: "You are not hosting a raid on this server",
+			}
+		}
+		return err
+	}
+
+	err = b.withRaidLobby(lobby.MessageID, func(lobby *repository.RaidLobby) error {
+		return b.closeRaidLobby(env.session, lobby, "closed by the host")
+	})
+	if err != nil {
+		return err
+	}
+
+	// the summary posted on the lobby's channel answers messages, slash
+	// commands still need an answer of their own.
+	if env.messageID != "" {
+		return nil
+	}
+	embed := b.newEmbed()
+	embed.Title = "Raid Closed"
+	embed.Description = fmt.Sprintf("Your raid for %s was closed.", raidLobbySubject(lobby))
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+// parseHostArgs builds the lobby from the den number or Pokémon name, and the
+// optional link code and gmax and shiny flags.
+func (b *Bot) parseHostArgs(args []string) (*repository.RaidLobby, error) {
+	lobby := &repository.RaidLobby{}
+	nameParts := make([]string, 0, len(args))
+	code := ""
+	for i, arg := range args {
+		arg = strings.ToLower(arg)
+		switch {
+		case arg == "gmax" || arg == "g-max" || arg == "gigantamax":
+			lobby.Gigantamax = true
+		case arg == "shiny":
+			lobby.Shiny = true
+		case i > 0 && isDigits(arg):
+			code += arg
+		default:
+			if strings.Contains(arg, "*") {
+				lobby.Shiny = true
+				arg = strings.ReplaceAll(arg, "*", "")
+			}
+			nameParts = append(nameParts, arg)
+		}
+	}
+
+	if code != "" {
+		if !linkCodeRegex.MatchString(code) {
+			return nil, botError{
+				title:   "Validation Error",
+				details: "Link codes must have 4 or 8 digits, i.e. `1234` or `1234 5678`",
+			}
+		}
+		lobby.Code = code
+	}
+
+	name := strings.Join(nameParts, " ")
+	if isDigits(name) {
+		if _, err := b.repository.Den(name); err != nil {
+			return nil, botError{
+				title:   "Den number not found",
+				details: fmt.Sprintf("Den %s could not be found.", name),
+			}
+		}
+		lobby.Den = name
+		return lobby, nil
+	}
+
+	pkm, err := b.findPokemon(name)
+	if err != nil {
+		return nil, err
+	}
+	if lobby.Gigantamax && !contains(pkm.Forms, "Gigantamax") {
+		return nil, botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("%s does not have a Gigantamax form", pkm.Name),
+		}
+	}
+	lobby.Pokemon = pkm.Name
+	return lobby, nil
+}
+
+func isDigits(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
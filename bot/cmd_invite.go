@@ -6,11 +6,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (b *Bot) handleInviteCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleInviteCmd(env *commandEnvironment) error {
 
 	embed := b.newEmbed()
 	embed.Title = "Rotom-B - Invite Link"
@@ -27,6 +23,5 @@ func (b *Bot) handleInviteCmd(
 			"[Add Rotom-B to your server!](%s)",
 		b.config.Discord.InviteURL,
 	)
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
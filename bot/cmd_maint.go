@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	maintReload      = "reload"
+	maintStats       = "stats"
+	maintGuild       = "guild"
+	maintMaintenance = "maintenance"
+	maintEvict       = "evict"
+	maintAnnounce    = "announce"
+)
+
+// handleMaintCmd handles the "maint" command, used by the bot maintainers to
+// operate the bot from Discord.
+func (b *Bot) handleMaintCmd(env *commandEnvironment) error {
+	if len(env.args) == 0 {
+		return botError{
+			title: "Validation Error",
+			details: fmt.Sprintf(
+				"Subcommand is required, must be one of %s",
+				strings.Join([]string{maintReload, maintStats, maintGuild, maintMaintenance, maintEvict, maintAnnounce}, ", "),
+			),
+		}
+	}
+
+	switch c := env.args[0]; c {
+	case maintReload:
+		return b.handleMaintReload(env)
+	case maintStats:
+		return b.handleMaintStats(env)
+	case maintGuild:
+		return b.handleMaintGuild(env)
+	case maintMaintenance:
+		return b.handleMaintMaintenance(env)
+	case maintEvict:
+		return b.handleMaintEvict(env)
+	case maintAnnounce:
+		return b.handleMaintAnnounce(env)
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: c + " is not a valid subcommand",
+		}
+	}
+}
+
+func (b *Bot) handleMaintReload(env *commandEnvironment) error {
+	if err := b.reloadData(); err != nil {
+		return botError{
+			title:   "Reload Failed",
+			details: "The current data was kept: " + err.Error(),
+		}
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Data Reloaded"
+	embed.Description = "Data loaded at " + b.repository.DataLoadedAt().Format(time.RFC822)
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleMaintStats(env *commandEnvironment) error {
+	shards, _ := b.shards.status()
+	shardLines := make([]string, 0, len(shards))
+	totalGuilds := 0
+	for _, shard := range shards {
+		guilds := 0
+		if shard.ID < len(b.sessions) {
+			guilds = len(b.sessions[shard.ID].State.Guilds)
+		}
+		totalGuilds += guilds
+
+		state := "connected"
+		if !shard.Connected {
+			state = "disconnected"
+		}
+		shardLines = append(shardLines, fmt.Sprintf("Shard %d: %s, %d guilds", shard.ID, state, guilds))
+	}
+	if len(shardLines) == 0 {
+		shardLines = append(shardLines, "N/A")
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Rotom-B Stats"
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Shards",
+			Value:  strings.Join(shardLines, "\n"),
+			Inline: false,
+		},
+		{
+			Name:   "Guilds",
+			Value:  fmt.Sprintf("%d", totalGuilds),
+			Inline: true,
+		},
+		{
+			Name:   "Requests Served",
+			Value:  fmt.Sprintf("%d", atomic.LoadUint64(&b.requestsServed)),
+			Inline: true,
+		},
+		{
+			Name:   "Uptime",
+			Value:  time.Since(b.startedAt).Round(time.Second).String(),
+			Inline: true,
+		},
+		{
+			Name:   "Data Loaded At",
+			Value:  b.repository.DataLoadedAt().Format(time.RFC822),
+			Inline: true,
+		},
+		{
+			Name:   "Maintenance Mode",
+			Value:  fmt.Sprintf("%v", b.inMaintenance()),
+			Inline: true,
+		},
+	}
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleMaintGuild(env *commandEnvironment) error {
+	if len(env.args) < 2 || env.args[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Guild ID is required",
+		}
+	}
+
+	settings, err := b.repository.GetGuildSettings(env.args[1])
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Not Found",
+				details: fmt.Sprintf("No settings found for guild %s", env.args[1]),
+			}
+		}
+		return err
+	}
+
+	embed, err := b.currentSettingsEmbed(env.session, settings)
+	if err != nil {
+		return err
+	}
+	embed.Description = fmt.Sprintf("Settings for guild %s", settings.DiscordID)
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleMaintMaintenance(env *commandEnvironment) error {
+	enabled := !b.inMaintenance()
+	if len(env.args) > 1 {
+		switch env.args[1] {
+		case "on":
+			enabled = true
+		case "off":
+			enabled = false
+		default:
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("%q is not valid, must be on or off", env.args[1]),
+			}
+		}
+	}
+	b.setMaintenance(enabled)
+
+	embed := b.newEmbed()
+	embed.Title = "Maintenance Mode"
+	embed.Description = "Maintenance mode is now off, everyone can use the bot again"
+	if enabled {
+		embed.Description = "Maintenance mode is now on, only maintainers can use the bot"
+	}
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleMaintEvict(env *commandEnvironment) error {
+	embed := b.newEmbed()
+	embed.Title = "Cache Evicted"
+	embed.Color = 0x00FF00
+	if len(env.args) > 1 && env.args[1] != "" {
+		b.repository.EvictGuildSettings(env.args[1])
+		embed.Description = fmt.Sprintf("Settings for guild %s were evicted from the cache", env.args[1])
+	} else {
+		b.repository.EvictAllGuildSettings()
+		embed.Description = "All guild settings were evicted from the cache"
+	}
+	return env.Reply(embed)
+}
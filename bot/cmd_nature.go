@@ -2,37 +2,26 @@ package bot
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (b *Bot) handleNatureCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleNatureCmd(env *commandEnvironment) error {
 	embed := b.newEmbed()
 	if len(env.args) == 0 {
 		embed.Title = "Pokémon Natures Chart (from Bulbapedia)"
 		embed.Image = &discordgo.MessageEmbedImage{
 			URL: "https://raw.githubusercontent.com/caquillo07/rotom-b-data/master/icons/natures.PNG",
 		}
-		_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-		return err
+		return env.Reply(embed)
 	}
 
-	nature := env.args[0]
-	natureInfo, ok := natures[strings.Title(nature)]
-	if !ok {
-		return botError{
-			title:   "Nature name not found",
-			details: fmt.Sprintf("Nature %s could not be found.", nature),
-		}
+	nature, natureInfo, err := b.findNature(env.args[0])
+	if err != nil {
+		return err
 	}
 
-	embed.Title = fmt.Sprintf("%s Nature Info", strings.Title(nature))
+	embed.Title = fmt.Sprintf("%s Nature Info", nature)
 	embed.Description = natureInfo
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	notifyAdd    = "add"
+	notifyRemove = "remove"
+	notifyList   = "list"
+)
+
+// handleNotifyCmd handles the "notify" command, which manages the Pokémon the
+// user gets pinged about when someone hosts a raid for them.
+func (b *Bot) handleNotifyCmd(env *commandEnvironment) error {
+	if env.guildID == "" {
+		return botError{
+			title:   "Not Available In DMs",
+			details: "Raid notifications belong to a server, use this command in one",
+		}
+	}
+
+	sub := ""
+	if len(env.args) > 0 {
+		sub = strings.ToLower(env.args[0])
+	}
+	switch sub {
+	case notifyAdd, notifyRemove:
+		return b.handleNotifyUpdate(env, sub)
+	case notifyList, "":
+		return b.handleNotifyList(env)
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Action must be one of %s, %s or %s", notifyAdd, notifyRemove, notifyList),
+		}
+	}
+}
+
+// handleNotifyUpdate subscribes or unsubscribes the user to the raids for a
+// Pokémon.
+//
+// i.e. notify add toxtricity gmax
+func (b *Bot) handleNotifyUpdate(env *commandEnvironment, action string) error {
+	if len(env.args) < 2 {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Pokémon is required, i.e. `%snotify %s toxtricity gmax`", env.commandPrefix, action),
+		}
+	}
+
+	subscription := &repository.RaidSubscription{
+		GuildID: env.guildID,
+		UserID:  env.user.ID,
+	}
+	nameParts := make([]string, 0, len(env.args))
+	for _, arg := range env.args[1:] {
+		switch arg = strings.ToLower(arg); {
+		case arg == "gmax" || arg == "g-max" || arg == "gigantamax":
+			subscription.Gigantamax = true
+		case arg == "shiny":
+			subscription.Shiny = true
+		default:
+			if strings.Contains(arg, "*") {
+				subscription.Shiny = true
+				arg = strings.ReplaceAll(arg, "*", "")
+			}
+			nameParts = append(nameParts, arg)
+		}
+	}
+
+	pkm, err := b.findPokemon(strings.Join(nameParts, " "))
+	if err != nil {
+		return err
+	}
+	if subscription.Gigantamax && !contains(pkm.Forms, "Gigantamax") {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("%s does not have a Gigantamax form", pkm.Name),
+		}
+	}
+	subscription.Pokemon = pkm.Name
+
+	subject := raidSubscriptionSubject(subscription)
+	if action == notifyRemove {
+		if err := b.repository.DeleteRaidSubscription(subscription); err != nil {
+			if err == repository.ErrRecordNotFound {
+				return botError{
+					title:   "Not Subscribed",
+					details: fmt.Sprintf("You are not getting notified of %s raids", subject),
+				}
+			}
+			return err
+		}
+		return b.replyNotifyUpdated(env, fmt.Sprintf("You will no longer get notified of %s raids", subject))
+	}
+
+	existing, err := b.repository.GetRaidSubscriptions(env.guildID, env.user.ID)
+	if err != nil {
+		return err
+	}
+	for _, s := range existing {
+		if s.Pokemon == subscription.Pokemon && s.Shiny == subscription.Shiny && s.Gigantamax == subscription.Gigantamax {
+			return botError{
+				title:   "Already Subscribed",
+				details: fmt.Sprintf("You are already getting notified of %s raids", subject),
+			}
+		}
+	}
+	if len(existing) >= b.config.Raids.MaxSubscriptionsPerUser {
+		return botError{
+			title: "Too Many Notifications",
+			details: fmt.Sprintf(
+				"You can get notified of up to %d Pokémon, remove some with `%snotify %s` before adding more",
+				b.config.Raids.MaxSubscriptionsPerUser, env.commandPrefix, notifyRemove,
+			),
+		}
+	}
+
+	if err := b.repository.CreateRaidSubscription(subscription); err != nil {
+		return err
+	}
+	return b.replyNotifyUpdated(env, fmt.Sprintf("You will get pinged when someone hosts a %s raid", subject))
+}
+
+func (b *Bot) handleNotifyList(env *commandEnvironment) error {
+	subscriptions, err := b.repository.GetRaidSubscriptions(env.guildID, env.user.ID)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(subscriptions))
+	for i, s := range subscriptions {
+		names[i] = "`" + raidSubscriptionSubject(s) + "`"
+	}
+	list := strings.Join(names, ", ")
+	if list == "" {
+		list = fmt.Sprintf("You are not getting notified of any raids, use `%snotify %s <pokemon>` to start", env.commandPrefix, notifyAdd)
+	}
+
+	embed := b.newEmbed()
+	embed.Title = fmt.Sprintf("Raid Notifications (%d/%d)", len(subscriptions), b.config.Raids.MaxSubscriptionsPerUser)
+	embed.Description = list
+	if env.guildSettings.QuietHours != "" {
+		embed.Description += fmt.Sprintf(
+			"\n\nNo notifications are sent from %s (%s).",
+			env.guildSettings.QuietHours,
+			guildLocation(env.guildSettings),
+		)
+	}
+	return env.Reply(embed)
+}
+
+func (b *Bot) replyNotifyUpdated(env *commandEnvironment, description string) error {
+	embed := b.newEmbed()
+	embed.Title = "Update Successful"
+	embed.Description = description
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+// notifyRaidSubscribers pings the users subscribed to the Pokémon of a raid
+// that was just posted, unless the guild is in its quiet hours. The host is
+// never pinged about their own raid.
+func (b *Bot) notifyRaidSubscribers(
+	s *discordgo.Session,
+	settings *repository.GuildSettings,
+	channelID, messageID, hostID string,
+	subscription *repository.RaidSubscription,
+) {
+	if subscription.Pokemon == "" || inQuietHours(settings, time.Now()) {
+		return
+	}
+
+	subscribers, err := b.repository.RaidSubscribers(
+		settings.DiscordID,
+		subscription.Pokemon,
+		subscription.Shiny,
+		subscription.Gigantamax,
+	)
+	if err != nil {
+		zap.L().Error("failed to get raid subscribers", zap.Error(err), zap.String("guild_id", settings.DiscordID))
+		return
+	}
+
+	subscribers = without(subscribers, hostID)
+	content := fmt.Sprintf("A **%s** raid was just posted!", raidSubscriptionSubject(subscription))
+	b.pingUsers(s, channelID, messageID, content, subscribers)
+}
+
+// raidPokemonFromTitle finds the Pokémon a scheduled raid is for from its
+// title, i.e. "Shiny Gmax Toxtricity at the usual spot". Only exact names are
+// matched, since the rest of the title is free text.
+func (b *Bot) raidPokemonFromTitle(title string) *repository.RaidSubscription {
+	subscription := &repository.RaidSubscription{}
+	words := strings.Fields(strings.ToLower(title))
+	for i, word := range words {
+		switch word {
+		case "gmax", "g-max", "gigantamax":
+			subscription.Gigantamax = true
+			continue
+		case "shiny":
+			subscription.Shiny = true
+			continue
+		}
+		if subscription.Pokemon != "" {
+			continue
+		}
+
+		// try the two word names first, like "mr mime"
+		if i < len(words)-1 {
+			if pkm, err := b.repository.Pokemon(word + " " + words[i+1]); err == nil {
+				subscription.Pokemon = pkm.Name
+				continue
+			}
+		}
+		if pkm, err := b.repository.Pokemon(strings.Trim(word, "*")); err == nil {
+			subscription.Pokemon = pkm.Name
+			subscription.Shiny = subscription.Shiny || strings.Contains(word, "*")
+		}
+	}
+	return subscription
+}
+
+// raidSubscriptionSubject describes the raids the subscription is for, i.e.
+// "Shiny Gigantamax Toxtricity".
+func raidSubscriptionSubject(subscription *repository.RaidSubscription) string {
+	return raidLobbySubject(&repository.RaidLobby{
+		Pokemon:    subscription.Pokemon,
+		Shiny:      subscription.Shiny,
+		Gigantamax: subscription.Gigantamax,
+	})
+}
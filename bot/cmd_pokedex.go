@@ -7,11 +7,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (b *Bot) handlePokedexCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handlePokedexCmd(env *commandEnvironment) error {
 
 	if len(env.args) == 0 {
 		return botError{
@@ -29,12 +25,9 @@ func (b *Bot) handlePokedexCmd(
 		pkmArgs.isShiny = strings.HasSuffix(env.args[0], "*") || strings.HasPrefix(env.args[0], "*")
 	}
 
-	pkm, err := b.repository.Pokemon(strings.ToLower(pkmArgs.name))
+	pkm, err := b.findPokemon(pkmArgs.name)
 	if err != nil {
-		return botError{
-			title:   "Pokémon not found",
-			details: fmt.Sprintf("Pokémon %s could not be found.", pkmArgs.name),
-		}
+		return err
 	}
 
 	urlPkmName := pkm.Name
@@ -82,7 +75,7 @@ func (b *Bot) handlePokedexCmd(
 	densShield := createJoinedPkmInfo("Shield", pkm.Dens.Shield)
 
 	embed := b.newEmbed()
-	embed.Title = fmt.Sprintf("%s Pokédex Info", strings.Title(pkmArgs.name))
+	embed.Title = fmt.Sprintf("%s Pokédex Info", pkm.Name)
 	embed.Image = &discordgo.MessageEmbedImage{
 		URL:    pkm.SpriteImage(pkmArgs.isShiny, pkmArgs.form),
 		Width:  300,
@@ -165,7 +158,7 @@ func (b *Bot) handlePokedexCmd(
 		Inline: false,
 	})
 
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
 
 func createJoinedPkmInfo(prefix string, info []string) string {
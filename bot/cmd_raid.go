@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	raidSchedule = "schedule"
+	raidList     = "list"
+	raidCancel   = "cancel"
+
+	// raidRemindPrefix overrides the reminder time of a scheduled raid,
+	// i.e. remind:30
+	raidRemindPrefix = "remind:"
+
+	raidTitleMaxLength  = 100
+	maxRaidRemindMinute = 24 * 60
+
+	// maxScheduledRaids is how many upcoming raids a guild can have
+	maxScheduledRaids = 25
+
+	// maxRaidScheduleAhead is how far ahead raids can be scheduled
+	maxRaidScheduleAhead = 30 * 24 * time.Hour
+)
+
+// handleRaidCmd handles the "raid" command, which schedules raids ahead of
+// time and lists the upcoming ones.
+func (b *Bot) handleRaidCmd(env *commandEnvironment) error {
+	if env.guildID == "" || env.session == nil {
+		return botError{
+			title:   "Not Available In DMs",
+			details: "Raids are scheduled on a server, use this command in one",
+		}
+	}
+
+	sub := ""
+	if len(env.args) > 0 {
+		sub = strings.ToLower(env.args[0])
+	}
+	switch sub {
+	case raidSchedule:
+		return b.handleRaidSchedule(env)
+	case raidCancel:
+		return b.handleRaidCancel(env)
+	case raidList, "":
+		return b.handleRaidList(env)
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Action must be one of %s, %s or %s", raidSchedule, raidList, raidCancel),
+		}
+	}
+}
+
+// handleRaidSchedule announces a raid ahead of time.
+//
+// i.e. raid schedule tomorrow 8pm EST Gmax Toxtricity
+func (b *Bot) handleRaidSchedule(env *commandEnvironment) error {
+	raidChannel := env.guildSettings.RaidChannelID
+	if raidChannel != "" && raidChannel != env.channelID {
+		return botError{
+			title:   "Wrong Channel",
+			details: fmt.Sprintf("Raids on this server are hosted on <#%s>", raidChannel),
+		}
+	}
+
+	now := time.Now()
+	startsAt, rest, err := parseStartTime(env.args[1:], now, guildLocation(env.guildSettings))
+	if err != nil {
+		return err
+	}
+	if startsAt.Before(now.Add(time.Minute)) || startsAt.After(now.Add(maxRaidScheduleAhead)) {
+		return botError{
+			title:   "Validation Error",
+			details: "Raids must start in the future, and up to 30 days from now",
+		}
+	}
+
+	remindMinutes := b.config.Raids.ReminderMinutes
+	titleParts := make([]string, 0, len(rest))
+	for _, arg := range rest {
+		if !strings.HasPrefix(strings.ToLower(arg), raidRemindPrefix) {
+			titleParts = append(titleParts, arg)
+			continue
+		}
+		minutes, err := strconv.Atoi(arg[len(raidRemindPrefix):])
+		if err != nil || minutes < 0 || minutes > maxRaidRemindMinute {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("Reminders can be sent from 0 to %d minutes before the raid, i.e. `remind:30`", maxRaidRemindMinute),
+			}
+		}
+		remindMinutes = minutes
+	}
+
+	title := strings.TrimSpace(strings.Join(titleParts, " "))
+	if title == "" || utf8.RuneCountInString(title) > raidTitleMaxLength {
+		return botError{
+			title: "Validation Error",
+			details: fmt.Sprintf(
+				"A title of up to %d characters is required, i.e. `%sraid %s 8pm EST Gmax Toxtricity`",
+				raidTitleMaxLength, env.commandPrefix, raidSchedule,
+			),
+		}
+	}
+
+	upcoming, err := b.repository.UpcomingRaidSchedules(env.guildID)
+	if err != nil {
+		return err
+	}
+	if len(upcoming) >= maxScheduledRaids {
+		return botError{
+			title:   "Too Many Raids",
+			details: fmt.Sprintf("A server can have up to %d upcoming raids, cancel some before adding more", maxScheduledRaids),
+		}
+	}
+
+	schedule := &repository.RaidSchedule{
+		GuildID:       env.guildID,
+		ChannelID:     env.channelID,
+		HostID:        env.user.ID,
+		Title:         title,
+		StartsAt:      startsAt,
+		RemindMinutes: remindMinutes,
+	}
+
+	msg, err := env.session.ChannelMessageSendEmbed(
+		env.channelID,
+		b.raidScheduleEmbed(schedule, env.guildSettings),
+		discordgo.WithContext(env.ctx),
+	)
+	if err != nil {
+		return err
+	}
+	schedule.MessageID = msg.ID
+	if err := b.repository.CreateRaidSchedule(schedule); err != nil {
+		// nothing stores the RSVPs without the schedule, so the announcement
+		// is removed before anyone reacts to it.
+		if delErr := env.session.ChannelMessageDelete(env.channelID, msg.ID); delErr != nil {
+			zap.L().Warn("failed to delete raid schedule message", zap.Error(delErr), zap.String("message_id", msg.ID))
+		}
+		return err
+	}
+	b.schedules.track(schedule.GuildID, schedule.MessageID)
+
+	// the ID used to cancel the raid is only known once it is saved
+	b.updateRaidScheduleMessage(env.session, schedule, env.guildSettings)
+
+	if err := env.session.MessageReactionAdd(env.channelID, msg.ID, raidJoinEmoji, discordgo.WithContext(env.ctx)); err != nil {
+		return err
+	}
+	b.notifyRaidSubscribers(env.session, env.guildSettings, env.channelID, msg.ID, env.user.ID, b.raidPokemonFromTitle(title))
+
+	// the announcement already answers commands sent as messages, slash
+	// commands still need an answer of their own.
+	if env.messageID != "" {
+		return nil
+	}
+	embed := b.newEmbed()
+	embed.Title = "Raid Scheduled"
+	embed.Description = fmt.Sprintf("%s is scheduled for <t:%d:F>.", title, startsAt.Unix())
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+// handleRaidCancel cancels a scheduled raid, only its host and the bot admins
+// can do it.
+//
+// i.e. raid cancel 12
+func (b *Bot) handleRaidCancel(env *commandEnvironment) error {
+	if len(env.args) < 2 {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("The raid ID is required, use `%sraid %s` to see them", env.commandPrefix, raidList),
+		}
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(env.args[1], "#"))
+	if err != nil {
+		return raidNotFound(env.args[1])
+	}
+	schedule, err := b.repository.GetRaidSchedule(env.guildID, id)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return raidNotFound(env.args[1])
+		}
+		return err
+	}
+	if schedule.CancelledAt != nil || schedule.StartsAt.Before(time.Now()) {
+		return botError{
+			title:   "Validation Error",
+			details: "The raid already started or was cancelled",
+		}
+	}
+
+	if schedule.HostID != env.user.ID {
+		isAdmin, err := userIsAdmin(env.session, env.guildSettings, env.guildID, env.user.ID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return botError{
+				title:   "Permission Denied",
+				details: "Only the raid's host and the server's bot admins can cancel it",
+			}
+		}
+	}
+
+	err = b.withRaidSchedule(schedule.MessageID, func(schedule *repository.RaidSchedule) error {
+		now := time.Now()
+		schedule.CancelledAt = &now
+		if err := b.repository.UpdateRaidSchedule(schedule); err != nil {
+			return err
+		}
+		b.schedules.untrack(schedule.MessageID)
+		b.updateRaidScheduleMessage(env.session, schedule, env.guildSettings)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Raid Cancelled"
+	embed.Description = fmt.Sprintf("%s was cancelled.", schedule.Title)
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleRaidList(env *commandEnvironment) error {
+	upcoming, err := b.repository.UpcomingRaidSchedules(env.guildID)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(upcoming))
+	for i, s := range upcoming {
+		lines[i] = fmt.Sprintf(
+			"`#%d` **%s** on %s (<t:%d:R>), hosted by <@%s>, %d going",
+			s.ID, s.Title, formatGuildTime(s.StartsAt, env.guildSettings), s.StartsAt.Unix(), s.HostID, len(s.RSVPs),
+		)
+	}
+	list := strings.Join(lines, "\n")
+	if list == "" {
+		list = fmt.Sprintf("There are no upcoming raids, schedule one with `%sraid %s <time> <title>`", env.commandPrefix, raidSchedule)
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Upcoming Raids"
+	embed.Description = list
+	return env.Reply(embed)
+}
+
+func raidNotFound(id string) error {
+	return botError{
+		title:   "Raid Not Found",
+		details: fmt.Sprintf("The raid %q does not exist", id),
+	}
+}
@@ -2,61 +2,57 @@ package bot
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
-	"time"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
 
 	"github.com/caquillo07/rotom-bot/repository"
 )
 
 const (
-	timeConverterURL = "https://www.worldtimeserver.com/convert_time_in_UTC.aspx"
+	timezoneListURL = "https://en.wikipedia.org/wiki/List_of_tz_database_time_zones"
 
 	actionAdd    = "add"
 	actionRemove = "remove"
 	actionReset  = "reset"
 )
 
-var channelIDRegex = regexp.MustCompile("<#(\\w+)>")
-
-func (b *Bot) handleConfigCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
-	channel, err := s.State.Channel(m.ChannelID)
-	if err != nil {
-		return err
-	}
-
-	guild, err := s.State.Guild(channel.GuildID)
-	if err != nil {
-		return err
-	}
-
-	guildSettings, err := b.getOrCreateGuildSettings(guild)
-	if err != nil {
-		return err
-	}
+var (
+	channelIDRegex = regexp.MustCompile("<#(\\w+)>")
+	roleIDRegex    = regexp.MustCompile("^(?:<@&(\\d+)>|(\\d+))$")
+	userIDRegex    = regexp.MustCompile("^(?:<@!?(\\d+)>|(\\d+))$")
+)
 
+func (b *Bot) handleConfigCmd(env *commandEnvironment) error {
+	guildSettings := env.guildSettings
 	if len(env.args) == 0 {
-		embed, err := b.currentSettingsEmbed(s, guildSettings)
+		embed, err := b.currentSettingsEmbed(env.session, guildSettings)
 		if err != nil {
 			return err
 		}
-		return sendEmbed(s, m.ChannelID, embed)
+		return env.Reply(embed)
 	}
 
 	switch c := env.args[0]; c {
+	case settingsDelete:
+		return b.handleSettingsDelete(env)
+	case settingsExport:
+		return b.handleSettingsExport(env)
 	case "prefix":
 		if len(env.args) < 2 || env.args[1] == "" {
 			return botError{
 				title:   "Validation Error",
-				details: "Prefix is required to update the setting",
+				details: "Prefix or action is required to update the setting",
 			}
 		}
-		guildSettings.BotPrefix = env.args[1]
+		prefixes, err := b.handlePrefixUpdate(env.args, guildSettings.BotPrefixes)
+		if err != nil {
+			return err
+		}
+		guildSettings.BotPrefixes = prefixes
 	case "listen":
 		if len(env.args) < 2 || env.args[1] == "" {
 			return botError{
@@ -66,18 +62,84 @@ func (b *Bot) handleConfigCmd(
 		}
 
 		// ignored the bad ones, but we can return this as a warning later.
-		_, err := handleListenUpdate(s, env.args, guildSettings)
+		_, err := handleChannelsUpdate(env.session, env.guildID, env.args, &guildSettings.ListeningChannels)
 		if err != nil {
 			return err
 		}
-
+	case "ignore-channel":
+		if len(env.args) < 2 || env.args[1] == "" {
+			return botError{
+				title:   "Validation Error",
+				details: "Channel or action is required to update the setting",
+			}
+		}
+		_, err := handleChannelsUpdate(env.session, env.guildID, env.args, &guildSettings.IgnoredChannels)
+		if err != nil {
+			return err
+		}
+	case "ignore-role":
+		roles, err := handleIDListUpdate(env.args, guildSettings.IgnoredRoles, roleIDRegex, "Role", func(id string) error {
+			return validateRole(env.session, env.guildID, id)
+		})
+		if err != nil {
+			return err
+		}
+		guildSettings.IgnoredRoles = roles
+	case "ignore-user":
+		users, err := handleIDListUpdate(env.args, guildSettings.IgnoredUsers, userIDRegex, "User", func(id string) error {
+			return validateUser(env.session, env.guildID, id)
+		})
+		if err != nil {
+			return err
+		}
+		guildSettings.IgnoredUsers = users
+	case "timezone":
+		if err := handleTimezoneUpdate(env.args, guildSettings); err != nil {
+			return err
+		}
+	case "quiet-hours":
+		if err := handleQuietHoursUpdate(env.args, guildSettings); err != nil {
+			return err
+		}
+	case "raid-channel":
+		if err := handleRaidChannelUpdate(env.session, env.guildID, env.args, guildSettings); err != nil {
+			return err
+		}
+	case "announcements":
+		if err := handleAnnouncementsChannelUpdate(env.session, env.guildID, env.args, guildSettings); err != nil {
+			return err
+		}
+	case "command":
+		if err := b.handleCommandToggleUpdate(env.args, guildSettings); err != nil {
+			return err
+		}
+	case "alias":
+		if err := b.handleAliasUpdate(env.args, guildSettings); err != nil {
+			return err
+		}
+	case "admin-role":
+		roles, err := handleIDListUpdate(env.args, guildSettings.BotAdminRoles, roleIDRegex, "Role", func(id string) error {
+			return validateRole(env.session, env.guildID, id)
+		})
+		if err != nil {
+			return err
+		}
+		guildSettings.BotAdminRoles = roles
+	case "admin-user":
+		users, err := handleIDListUpdate(env.args, guildSettings.BotAdminUsers, userIDRegex, "User", func(id string) error {
+			return validateUser(env.session, env.guildID, id)
+		})
+		if err != nil {
+			return err
+		}
+		guildSettings.BotAdminUsers = users
 	default:
 		return botError{
 			title:   "Validation Error",
 			details: c + " is not a valid setting",
 		}
 	}
-	guildSettings.LastUpdatedBy = m.Author.ID
+	guildSettings.LastUpdatedBy = env.user.ID
 	if err := b.repository.UpdateGuildSettings(guildSettings); err != nil {
 		return err
 	}
@@ -86,7 +148,7 @@ func (b *Bot) handleConfigCmd(
 	embed.Title = "Update Successful"
 	embed.Description = "Setting was updated successfully"
 	embed.Color = 0x00FF00
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
 
 func (b *Bot) currentSettingsEmbed(s *discordgo.Session, settings *repository.GuildSettings) (*discordgo.MessageEmbed, error) {
@@ -99,38 +161,78 @@ func (b *Bot) currentSettingsEmbed(s *discordgo.Session, settings *repository.Gu
 		lastUpdatedBy = user.String()
 
 		if t := settings.UpdatedAt; !t.IsZero() {
-			lastUpdatedBy += fmt.Sprintf(
-				" on [%s](%s?y=%d&mo=%d&d=%d&h=%d&mn=%d)",
-				t.Format(time.RFC822),
-				timeConverterURL,
-				t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(),
-			)
+			lastUpdatedBy += " on " + formatGuildTime(t, settings)
 		}
 	}
 
 	embed := b.newEmbed()
 	embed.Title = settings.Name + "'s settings"
 	embed.Description = "List of settings and their values for server"
-	listeningOn := ""
-	for i, c := range settings.ListeningChannels {
-		listeningOn += c.Name
-		if len(settings.ListeningChannels)-1 != i {
-			listeningOn += ", "
-		}
-	}
-	if listeningOn == "" {
-		listeningOn = "N/A"
-	}
 
 	embed.Fields = []*discordgo.MessageEmbedField{
 		{
-			Name:   "Prefix",
-			Value:  settings.BotPrefix,
+			Name:   "Prefixes",
+			Value:  "`" + strings.Join(settings.BotPrefixes, "`, `") + "`",
 			Inline: false,
 		},
 		{
 			Name:   "Listening on",
-			Value:  listeningOn,
+			Value:  channelNamesList(settings.ListeningChannels),
+			Inline: false,
+		},
+		{
+			Name:   "Ignored Channels",
+			Value:  channelNamesList(settings.IgnoredChannels),
+			Inline: false,
+		},
+		{
+			Name:   "Ignored Roles",
+			Value:  mentionList(settings.IgnoredRoles, "<@&%s>"),
+			Inline: false,
+		},
+		{
+			Name:   "Ignored Users",
+			Value:  mentionList(settings.IgnoredUsers, "<@%s>"),
+			Inline: false,
+		},
+		{
+			Name:   "Timezone",
+			Value:  guildLocation(settings).String(),
+			Inline: false,
+		},
+		{
+			Name:   "Quiet Hours",
+			Value:  valueOrNA(settings.QuietHours),
+			Inline: false,
+		},
+		{
+			Name:   "Raid Channel",
+			Value:  mentionList(nonEmpty(settings.RaidChannelID), "<#%s>"),
+			Inline: false,
+		},
+		{
+			Name:   "Announcements Channel",
+			Value:  mentionList(nonEmpty(settings.AnnouncementsChannelID), "<#%s>"),
+			Inline: false,
+		},
+		{
+			Name:   "Disabled Commands",
+			Value:  disabledCommandsList(settings.DisabledCommands),
+			Inline: false,
+		},
+		{
+			Name:   "Command Aliases",
+			Value:  aliasesList(settings.CommandAliases),
+			Inline: false,
+		},
+		{
+			Name:   "Admin Roles",
+			Value:  mentionList(settings.BotAdminRoles, "<@&%s>"),
+			Inline: false,
+		},
+		{
+			Name:   "Admin Users",
+			Value:  mentionList(settings.BotAdminUsers, "<@%s>"),
 			Inline: false,
 		},
 		{
@@ -156,11 +258,15 @@ func getActionFromArgs(args []string) string {
 	return c
 }
 
-func handleListenUpdate(s *discordgo.Session, args []string, settings *repository.GuildSettings) ([]string, error) {
+// handleChannelsUpdate applies the action in the arguments to the given list
+// of channels, or replaces the list with the channels in the arguments if there
+// is no action. It returns the arguments that were not valid channels.
+// Channels from other guilds are rejected.
+func handleChannelsUpdate(s *discordgo.Session, guildID string, args []string, channels *repository.GuildSettingChannels) ([]string, error) {
 	action := getActionFromArgs(args)
 	// if we are resetting, just empty and return early
 	if action == actionReset {
-		settings.ListeningChannels = nil
+		*channels = nil
 		return nil, nil
 	}
 
@@ -202,6 +308,12 @@ func handleListenUpdate(s *discordgo.Session, args []string, settings *repositor
 		if err != nil {
 			return nil, err
 		}
+		if channel.GuildID != guildID {
+			return nil, botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("Channel %q must be on this server", channel.Name),
+			}
+		}
 		finalChannels[i] = &repository.GuildSettingChannel{
 			ID:   channel.ID,
 			Name: channel.Name,
@@ -210,7 +322,7 @@ func handleListenUpdate(s *discordgo.Session, args []string, settings *repositor
 
 	// if we have no actions, but cleaned IDs, just do a replace all
 	if action == "" {
-		settings.ListeningChannels = finalChannels
+		*channels = finalChannels
 		return badChannelIDs, nil
 	}
 
@@ -218,16 +330,16 @@ func handleListenUpdate(s *discordgo.Session, args []string, settings *repositor
 	for _, channel := range finalChannels {
 		switch action {
 		case actionAdd:
-			settings.ListeningChannels = append(settings.ListeningChannels, channel)
+			*channels = append(*channels, channel)
 		case actionRemove:
-			cleanedList := make([]*repository.GuildSettingChannel, 0, len(settings.ListeningChannels))
-			for _, c := range settings.ListeningChannels {
+			cleanedList := make([]*repository.GuildSettingChannel, 0, len(*channels))
+			for _, c := range *channels {
 				if c.ID == channel.ID {
 					continue
 				}
 				cleanedList = append(cleanedList, c)
 			}
-			settings.ListeningChannels = cleanedList
+			*channels = cleanedList
 		case "":
 			// ignore.
 		}
@@ -235,3 +347,333 @@ func handleListenUpdate(s *discordgo.Session, args []string, settings *repositor
 
 	return badChannelIDs, nil
 }
+
+// handleIDListUpdate applies the add, remove or reset action in the arguments
+// to the given list of role or user IDs, and returns the updated list. Every
+// ID is checked with the validate func before it is added.
+func handleIDListUpdate(
+	args []string,
+	list pq.StringArray,
+	idRegex *regexp.Regexp,
+	kind string,
+	validate func(id string) error,
+) (pq.StringArray, error) {
+	action := ""
+	if len(args) > 1 {
+		action = getActionFromArgs(args)
+	}
+	if action == "" {
+		return nil, botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Action is required, must be one of %s, %s or %s", actionAdd, actionRemove, actionReset),
+		}
+	}
+	if action == actionReset {
+		return nil, nil
+	}
+	if len(args) < 3 {
+		return nil, botError{
+			title:   "Validation Error",
+			details: kind + "(s) are required to update the setting",
+		}
+	}
+
+	ids := make([]string, 0, len(args)-2)
+	for _, arg := range args[2:] {
+		match := idRegex.FindStringSubmatch(arg)
+		if match == nil {
+			return nil, botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("%s %q is not a valid %s", kind, arg, strings.ToLower(kind)),
+			}
+		}
+		id := match[1]
+		if id == "" {
+			id = match[2]
+		}
+		ids = append(ids, id)
+	}
+
+	updated := make(pq.StringArray, 0, len(list)+len(ids))
+	switch action {
+	case actionAdd:
+		updated = append(updated, list...)
+		for _, id := range ids {
+			if contains(updated, id) {
+				continue
+			}
+			if err := validate(id); err != nil {
+				return nil, err
+			}
+			updated = append(updated, id)
+		}
+	case actionRemove:
+		for _, id := range list {
+			if !contains(ids, id) {
+				updated = append(updated, id)
+			}
+		}
+	}
+	return updated, nil
+}
+
+// handleTimezoneUpdate sets the timezone times are shown and parsed in.
+//
+// i.e. settings timezone America/New_York
+func handleTimezoneUpdate(args []string, settings *repository.GuildSettings) error {
+	if len(args) < 2 || args[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Timezone or reset is required to update the setting",
+		}
+	}
+	if args[1] == actionReset {
+		settings.Timezone = ""
+		return nil
+	}
+
+	loc, ok := loadTimezone(args[1])
+	if !ok {
+		return botError{
+			title: "Validation Error",
+			details: fmt.Sprintf(
+				"%q is not a valid timezone, use a name from %s like `America/New_York`, or an abbreviation like `EST`",
+				args[1], timezoneListURL,
+			),
+		}
+	}
+	settings.Timezone = loc.String()
+	return nil
+}
+
+// handleQuietHoursUpdate sets the time range raid notifications are not sent
+// in, on the guild's timezone.
+//
+// i.e. settings quiet-hours 23:00-07:00
+func handleQuietHoursUpdate(args []string, settings *repository.GuildSettings) error {
+	if len(args) < 2 || args[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Time range or reset is required to update the setting",
+		}
+	}
+	if args[1] == actionReset {
+		settings.QuietHours = ""
+		return nil
+	}
+
+	quietHours, ok := parseQuietHours(strings.Join(args[1:], ""))
+	if !ok {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("%q is not a valid time range, i.e. `23:00-07:00` or `11pm-7am`", strings.Join(args[1:], " ")),
+		}
+	}
+	settings.QuietHours = quietHours
+	return nil
+}
+
+// handleRaidChannelUpdate sets the channel Max Raids are hosted on, which
+// must be one of the listening channels if the guild has any.
+//
+// i.e. settings raid-channel #raids
+func handleRaidChannelUpdate(s *discordgo.Session, guildID string, args []string, settings *repository.GuildSettings) error {
+	if len(args) < 2 || args[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Channel or reset is required to update the setting",
+		}
+	}
+	if args[1] == actionReset {
+		settings.RaidChannelID = ""
+		return nil
+	}
+
+	match := channelIDRegex.FindStringSubmatch(args[1])
+	if len(match) != 2 || match[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Channel %q is not a valid channel", args[1]),
+		}
+	}
+	channel, err := s.Channel(match[1])
+	if err != nil {
+		return err
+	}
+	if channel.GuildID != guildID {
+		return botError{
+			title:   "Validation Error",
+			details: "The raid channel must be on this server",
+		}
+	}
+	if len(settings.ListeningChannels) > 0 && !inListenChannels(channel.ID, settings.ListeningChannels) {
+		return botError{
+			title:   "Validation Error",
+			details: "The raid channel must be one of the channels the bot listens on",
+		}
+	}
+	settings.RaidChannelID = channel.ID
+	return nil
+}
+
+// handleAnnouncementsChannelUpdate sets the channel the bot's update
+// announcements are posted on, the guild gets none until one is set.
+//
+// i.e. settings announcements #bot-news
+func handleAnnouncementsChannelUpdate(s *discordgo.Session, guildID string, args []string, settings *repository.GuildSettings) error {
+	if len(args) < 2 || args[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: "Channel or reset is required to update the setting",
+		}
+	}
+	if args[1] == actionReset {
+		settings.AnnouncementsChannelID = ""
+		return nil
+	}
+
+	match := channelIDRegex.FindStringSubmatch(args[1])
+	if len(match) != 2 || match[1] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Channel %q is not a valid channel", args[1]),
+		}
+	}
+	channel, err := s.Channel(match[1])
+	if err != nil {
+		return err
+	}
+	if channel.GuildID != guildID {
+		return botError{
+			title:   "Validation Error",
+			details: "The announcements channel must be on this server",
+		}
+	}
+	settings.AnnouncementsChannelID = channel.ID
+	return nil
+}
+
+// validateRole makes sure the role exists in the guild.
+func validateRole(s *discordgo.Session, guildID, roleID string) error {
+	if _, err := s.State.Role(guildID, roleID); err == nil {
+		return nil
+	}
+
+	roles, err := s.GuildRoles(guildID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if role.ID == roleID {
+			return nil
+		}
+	}
+	return botError{
+		title:   "Validation Error",
+		details: fmt.Sprintf("Role %s does not exist in this server", roleID),
+	}
+}
+
+// validateUser makes sure the user is a member of the guild.
+func validateUser(s *discordgo.Session, guildID, userID string) error {
+	if _, err := s.State.Member(guildID, userID); err == nil {
+		return nil
+	}
+
+	if _, err := s.GuildMember(guildID, userID); err != nil {
+		if restErr, ok := err.(*discordgo.RESTError); ok && restErr.Response != nil &&
+			restErr.Response.StatusCode == http.StatusNotFound {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("User %s is not a member of this server", userID),
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// channelNamesList formats the channel names for the settings embed, or N/A
+// if there are none.
+func channelNamesList(channels repository.GuildSettingChannels) string {
+	if len(channels) == 0 {
+		return "N/A"
+	}
+	names := make([]string, len(channels))
+	for i, c := range channels {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// nonEmpty returns a list with the given ID, or an empty one if there is no
+// ID.
+func nonEmpty(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return []string{id}
+}
+
+// mentionList formats the IDs as mentions with the given format, or N/A if
+// there are none.
+func mentionList(ids []string, format string) string {
+	if len(ids) == 0 {
+		return "N/A"
+	}
+	mentions := make([]string, len(ids))
+	for i, id := range ids {
+		mentions[i] = fmt.Sprintf(format, id)
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// handlePrefixUpdate applies the add, remove or reset action in the arguments
+// to the guild's prefixes, and returns the updated list. Without an action,
+// the given prefix replaces all of them.
+func (b *Bot) handlePrefixUpdate(args []string, prefixes pq.StringArray) (pq.StringArray, error) {
+	action := getActionFromArgs(args)
+	switch action {
+	case actionReset:
+		return pq.StringArray{b.config.Bot.Prefix}, nil
+	case "":
+		return pq.StringArray{args[1]}, nil
+	}
+
+	if len(args) < 3 {
+		return nil, botError{
+			title:   "Validation Error",
+			details: "Prefix(es) are required to update the setting",
+		}
+	}
+
+	updated := make(pq.StringArray, 0, len(prefixes)+len(args)-2)
+	switch action {
+	case actionAdd:
+		updated = append(updated, prefixes...)
+		for _, p := range args[2:] {
+			if p != "" && !contains(updated, p) {
+				updated = append(updated, p)
+			}
+		}
+		if len(updated) > maxPrefixes {
+			return nil, botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("A server can have up to %d prefixes", maxPrefixes),
+			}
+		}
+	case actionRemove:
+		for _, p := range prefixes {
+			if !contains(args[2:], p) {
+				updated = append(updated, p)
+			}
+		}
+		if len(updated) == 0 {
+			return nil, botError{
+				title:   "Validation Error",
+				details: "At least one prefix is required, mentioning the bot always works as well",
+			}
+		}
+	}
+	return updated, nil
+}
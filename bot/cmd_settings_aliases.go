@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+// maxGuildAliases is the maximum number of aliases a guild can have.
+const maxGuildAliases = 25
+
+// resolveCommand finds the command with the given name, following the built
+// in aliases and the guild's own aliases.
+func (b *Bot) resolveCommand(name string, settings *repository.GuildSettings) (*command, bool) {
+	botCmd, ok := b.commands[name]
+	if !ok {
+		target, isAlias := settings.CommandAliases[name]
+		if !isAlias {
+			return nil, false
+		}
+		botCmd, ok = b.commands[target]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if botCmd.alias != "" {
+		botCmd, ok = b.commands[botCmd.alias]
+	}
+	return botCmd, ok
+}
+
+// handleAliasUpdate adds or removes one of the guild's aliases.
+//
+// i.e. settings alias add c catch
+func (b *Bot) handleAliasUpdate(args []string, settings *repository.GuildSettings) error {
+	action := ""
+	if len(args) > 1 {
+		action = getActionFromArgs(args)
+	}
+
+	switch action {
+	case actionReset:
+		settings.CommandAliases = nil
+		return nil
+	case actionRemove:
+		if len(args) < 3 {
+			return botError{
+				title:   "Validation Error",
+				details: "Alias is required to update the setting",
+			}
+		}
+		alias := strings.ToLower(args[2])
+		if _, ok := settings.CommandAliases[alias]; !ok {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("The alias %q does not exist", alias),
+			}
+		}
+		delete(settings.CommandAliases, alias)
+		return nil
+	case actionAdd:
+		if len(args) < 4 {
+			return botError{
+				title:   "Validation Error",
+				details: "Alias and command are required, i.e. `settings alias add c catch`",
+			}
+		}
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Action is required, must be one of %s, %s or %s", actionAdd, actionRemove, actionReset),
+		}
+	}
+
+	alias, target := strings.ToLower(args[2]), strings.ToLower(args[3])
+	if err := b.validateNewCommandName(alias, settings); err != nil {
+		return err
+	}
+
+	cmd, ok := b.resolveCommand(target, settings)
+	if !ok || cmd.botAdminOnly {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("The command %q does not exist", target),
+		}
+	}
+	if len(settings.CommandAliases) >= maxGuildAliases {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("A server can have up to %d aliases", maxGuildAliases),
+		}
+	}
+
+	if settings.CommandAliases == nil {
+		settings.CommandAliases = make(repository.GuildCommandAliases)
+	}
+	settings.CommandAliases[alias] = cmd.name
+	return nil
+}
+
+// validateNewCommandName makes sure a new tag or alias name is valid, and
+// does not shadow any of the built in commands and aliases, or the guild's
+// own aliases and tags.
+func (b *Bot) validateNewCommandName(name string, settings *repository.GuildSettings) error {
+	if !tagNameRegex.MatchString(name) {
+		return botError{
+			title:   "Validation Error",
+			details: "Names can be up to 32 characters long, and only have letters, numbers, - and _",
+		}
+	}
+
+	_, isCommand := b.commands[name]
+	_, isAlias := settings.CommandAliases[name]
+	if isCommand || isAlias || contains(tagSubcommands, name) || b.isTag(settings.DiscordID, name) {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("%q is already used by a command, alias or tag, choose a different name", name),
+		}
+	}
+	return nil
+}
+
+// guildAliasesFor returns the guild's aliases of the given command, sorted.
+func guildAliasesFor(settings *repository.GuildSettings, name string) []string {
+	aliases := make([]string, 0)
+	for alias, target := range settings.CommandAliases {
+		if target == name {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// aliasesList formats the guild's aliases for the settings embed.
+func aliasesList(aliases repository.GuildCommandAliases) string {
+	if len(aliases) == 0 {
+		return "N/A"
+	}
+
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, alias := range names {
+		lines[i] = fmt.Sprintf("`%s` → `%s`", alias, aliases[alias])
+	}
+	return strings.Join(lines, "\n")
+}
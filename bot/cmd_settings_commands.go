@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	actionEnable  = "enable"
+	actionDisable = "disable"
+)
+
+// handleCommandToggleUpdate enables or disables a command or alias on the
+// guild, or only on the given channels.
+//
+// i.e. settings command disable sprite #raids
+func (b *Bot) handleCommandToggleUpdate(args []string, settings *repository.GuildSettings) error {
+	if len(args) < 3 || (args[1] != actionEnable && args[1] != actionDisable) {
+		return botError{
+			title: "Validation Error",
+			details: fmt.Sprintf(
+				"Action and command are required, i.e. `settings command %s <command> [#channel]`",
+				actionDisable,
+			),
+		}
+	}
+
+	action, name := args[1], strings.ToLower(args[2])
+	cmd, ok := b.resolveCommand(name, settings)
+	if !ok || cmd.botAdminOnly {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("The command %q does not exist", name),
+		}
+	}
+
+	// the settings command can't be disabled, otherwise there would be no
+	// way of enabling it back.
+	if cmd.name == "settings" {
+		return botError{
+			title:   "Validation Error",
+			details: "The settings command can't be disabled",
+		}
+	}
+
+	channelIDs := make([]string, 0, len(args)-3)
+	for _, arg := range args[3:] {
+		match := channelIDRegex.FindStringSubmatch(arg)
+		if len(match) != 2 || match[1] == "" {
+			return botError{
+				title:   "Validation Error",
+				details: fmt.Sprintf("Channel %q is not a valid channel", arg),
+			}
+		}
+		channelIDs = append(channelIDs, match[1])
+	}
+
+	if action == actionDisable {
+		settings.DisabledCommands = disableCommand(settings.DisabledCommands, name, channelIDs)
+		return nil
+	}
+
+	disabled, err := enableCommand(settings.DisabledCommands, name, channelIDs)
+	if err != nil {
+		return err
+	}
+	settings.DisabledCommands = disabled
+	return nil
+}
+
+// disableCommand disables the command on the given channels, or on every
+// channel if there are none.
+func disableCommand(
+	disabled repository.GuildDisabledCommands,
+	name string,
+	channelIDs []string,
+) repository.GuildDisabledCommands {
+	existing := disabled.Find(name)
+	if existing == nil {
+		return append(disabled, &repository.GuildDisabledCommand{Name: name, ChannelIDs: channelIDs})
+	}
+
+	// already disabled everywhere, nothing else to do
+	if len(existing.ChannelIDs) == 0 {
+		return disabled
+	}
+	if len(channelIDs) == 0 {
+		existing.ChannelIDs = nil
+		return disabled
+	}
+	for _, id := range channelIDs {
+		if !contains(existing.ChannelIDs, id) {
+			existing.ChannelIDs = append(existing.ChannelIDs, id)
+		}
+	}
+	return disabled
+}
+
+// enableCommand enables the command back on the given channels, or on every
+// channel if there are none.
+func enableCommand(
+	disabled repository.GuildDisabledCommands,
+	name string,
+	channelIDs []string,
+) (repository.GuildDisabledCommands, error) {
+	existing := disabled.Find(name)
+	if existing == nil {
+		return disabled, nil
+	}
+
+	if len(channelIDs) > 0 {
+		if len(existing.ChannelIDs) == 0 {
+			return nil, botError{
+				title: "Validation Error",
+				details: fmt.Sprintf(
+					"%s is disabled on every channel, enable it everywhere first with `settings command %s %s`",
+					name, actionEnable, name,
+				),
+			}
+		}
+
+		remaining := make([]string, 0, len(existing.ChannelIDs))
+		for _, id := range existing.ChannelIDs {
+			if !contains(channelIDs, id) {
+				remaining = append(remaining, id)
+			}
+		}
+		existing.ChannelIDs = remaining
+		if len(remaining) > 0 {
+			return disabled, nil
+		}
+	}
+
+	updated := make(repository.GuildDisabledCommands, 0, len(disabled))
+	for _, c := range disabled {
+		if c.Name != name {
+			updated = append(updated, c)
+		}
+	}
+	return updated, nil
+}
+
+// commandDisabled checks whether the command is disabled on the channel,
+// either by the name it was invoked with, or by the name of the command the
+// alias points to.
+func commandDisabled(settings *repository.GuildSettings, invokedAs string, cmd *command, channelID string) bool {
+	return settings.DisabledCommands.IsDisabled(invokedAs, channelID) ||
+		settings.DisabledCommands.IsDisabled(cmd.name, channelID)
+}
+
+// disabledCommandsList formats the disabled commands for the settings embed.
+func disabledCommandsList(disabled repository.GuildDisabledCommands) string {
+	if len(disabled) == 0 {
+		return "N/A"
+	}
+
+	lines := make([]string, len(disabled))
+	for i, c := range disabled {
+		where := "everywhere"
+		if len(c.ChannelIDs) > 0 {
+			where = mentionList(c.ChannelIDs, "<#%s>")
+		}
+		lines[i] = fmt.Sprintf("`%s` on %s", c.Name, where)
+	}
+	return strings.Join(lines, "\n")
+}
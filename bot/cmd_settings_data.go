@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/patrickmn/go-cache"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	settingsDelete  = "delete"
+	settingsExport  = "export"
+	deleteConfirm   = "confirm"
+	deleteCancelled = "cancel"
+)
+
+// handleSettingsDelete deletes everything stored about the guild. It takes two
+// steps, the first one only asks for a confirmation which must come from the
+// same user before it expires.
+func (b *Bot) handleSettingsDelete(env *commandEnvironment) error {
+	step := ""
+	if len(env.args) > 1 {
+		step = env.args[1]
+	}
+
+	switch step {
+	case "":
+		b.pendingDeletions.Set(env.guildID, env.user.ID, cache.DefaultExpiration)
+
+		embed := b.newEmbed()
+		embed.Title = "Delete Server Data"
+		embed.Description = fmt.Sprintf(
+			"This will permanently delete every setting and record Rotom-B stores about this server.\n\n"+
+				"Run `%ssettings delete %s` within %d seconds to continue, or `%ssettings delete %s` to keep the data.",
+			env.commandPrefix, deleteConfirm, int(deleteConfirmationTimeout.Seconds()),
+			env.commandPrefix, deleteCancelled,
+		)
+		embed.Color = b.config.Bot.WarningEmbedColor
+		return env.Reply(embed)
+	case deleteCancelled:
+		b.pendingDeletions.Delete(env.guildID)
+
+		embed := b.newEmbed()
+		embed.Title = "Delete Cancelled"
+		embed.Description = "The server data was not deleted"
+		return env.Reply(embed)
+	case deleteConfirm:
+		requestedBy, found := b.pendingDeletions.Get(env.guildID)
+		if !found || requestedBy.(string) != env.user.ID {
+			return botError{
+				title: "Nothing To Confirm",
+				details: fmt.Sprintf(
+					"Run `%ssettings delete` first, the confirmation must come from the same user",
+					env.commandPrefix,
+				),
+			}
+		}
+		b.pendingDeletions.Delete(env.guildID)
+
+		if err := b.deleteGuildData(env.guildID); err != nil {
+			return err
+		}
+
+		embed := b.newEmbed()
+		embed.Title = "Server Data Deleted"
+		embed.Description = "Every setting and record about this server was deleted. " +
+			"Using the bot again will start over with the default settings."
+		embed.Color = 0x00FF00
+		return env.Reply(embed)
+	default:
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("%q is not valid, must be %s or %s", step, deleteConfirm, deleteCancelled),
+		}
+	}
+}
+
+// handleSettingsExport sends everything stored about the guild as a JSON file.
+func (b *Bot) handleSettingsExport(env *commandEnvironment) error {
+	data, err := b.repository.ExportGuildData(env.guildID)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Nothing To Export",
+				details: "Rotom-B does not store any data about this server",
+			}
+		}
+		return err
+	}
+
+	rawJSON, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	embed := b.newEmbed()
+	embed.Title = "Server Data Export"
+	embed.Description = "Attached is everything Rotom-B stores about this server"
+	return env.ReplyFile(
+		fmt.Sprintf("rotom-b-%s.json", env.guildID),
+		bytes.NewReader(rawJSON),
+		embed,
+	)
+}
+
+// deleteGuildData removes everything stored about the guild. Its raid
+// messages stop being tracked first, so no reaction being handled on them
+// can save a record again once it is deleted.
+func (b *Bot) deleteGuildData(guildID string) error {
+	b.lobbies.untrackGuild(guildID)
+	b.schedules.untrackGuild(guildID)
+	return b.repository.DeleteGuildData(guildID)
+}
@@ -1,17 +1,12 @@
 package bot
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (b *Bot) handleSpriteCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleSpriteCmd(env *commandEnvironment) error {
 
 	if len(env.args) == 0 {
 		return botError{
@@ -29,12 +24,9 @@ func (b *Bot) handleSpriteCmd(
 		pkmArgs.isShiny = strings.HasSuffix(env.args[0], "*") || strings.HasPrefix(env.args[0], "*")
 	}
 
-	pkm, err := b.repository.Pokemon(strings.ToLower(pkmArgs.name))
+	pkm, err := b.findPokemon(pkmArgs.name)
 	if err != nil {
-		return botError{
-			title:   "Pokémon not found",
-			details: fmt.Sprintf("Pokémon %s could not be found.", pkmArgs.name),
-		}
+		return err
 	}
 
 	var embedTitle string
@@ -53,5 +45,5 @@ func (b *Bot) handleSpriteCmd(
 		Width:  300,
 		Height: 300,
 	}
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
@@ -0,0 +1,216 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	tagAdd    = "add"
+	tagEmbed  = "embed"
+	tagEdit   = "edit"
+	tagRemove = "remove"
+	tagList   = "list"
+
+	// tagMaxLength is the longest a tag's content can be, which is the most
+	// Discord allows on a single message.
+	tagMaxLength = 2000
+)
+
+var (
+	tagNameRegex = regexp.MustCompile("^[a-z0-9_-]{1,32}$")
+
+	// tagSubcommands can't be used as tag names, since "tag <name>" would be
+	// ambiguous.
+	tagSubcommands = []string{tagAdd, tagEmbed, tagEdit, tagRemove, tagList}
+)
+
+// handleTagCmd handles the "tag" command, which shows, creates and manages
+// the guild's tags.
+func (b *Bot) handleTagCmd(env *commandEnvironment) error {
+	if env.guildID == "" {
+		return botError{
+			title:   "Not Available In DMs",
+			details: "Tags belong to a server, use this command in one",
+		}
+	}
+	if len(env.args) == 0 || env.args[0] == "" {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Tag name is required, use `%stag %s` to see them all", env.commandPrefix, tagList),
+		}
+	}
+
+	switch sub := strings.ToLower(env.args[0]); sub {
+	case tagList:
+		return b.handleTagList(env)
+	case tagAdd, tagEmbed, tagEdit, tagRemove:
+		isAdmin, err := userIsAdmin(env.session, env.guildSettings, env.guildID, env.user.ID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return botError{
+				title:   "Permission Denied",
+				details: "Only the server's bot admins can manage tags",
+			}
+		}
+
+		if sub == tagRemove {
+			return b.handleTagRemove(env)
+		}
+		return b.handleTagSave(env, sub)
+	default:
+		return b.handleTagShow(env, sub)
+	}
+}
+
+func (b *Bot) handleTagShow(env *commandEnvironment, name string) error {
+	tag, err := b.repository.GetTag(env.guildID, name)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Tag Not Found",
+				details: fmt.Sprintf("The tag %q does not exist", name),
+			}
+		}
+		return err
+	}
+
+	if !tag.Embed {
+		return env.ReplyText(tag.Content)
+	}
+	embed := b.newEmbed()
+	embed.Title = tag.Name
+	embed.Description = tag.Content
+	return env.Reply(embed)
+}
+
+func (b *Bot) handleTagList(env *commandEnvironment) error {
+	tags, err := b.repository.GetTags(env.guildID)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = "`" + t.Name + "`"
+	}
+	list := strings.Join(names, ", ")
+	if list == "" {
+		list = fmt.Sprintf("This server has no tags yet, admins can add them with `%stag %s <name> <content>`",
+			env.commandPrefix, tagAdd)
+	}
+
+	embed := b.newEmbed()
+	embed.Title = fmt.Sprintf("Tags (%d/%d)", len(tags), b.config.Bot.MaxTagsPerGuild)
+	embed.Description = list
+	return env.Reply(embed)
+}
+
+// handleTagSave creates or edits a tag, the content is everything after the
+// name.
+//
+// i.e. tag add rules No raid sniping, be nice!
+func (b *Bot) handleTagSave(env *commandEnvironment, sub string) error {
+	if len(env.args) < 3 {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Name and content are required, i.e. `%stag %s <name> <content>`", env.commandPrefix, sub),
+		}
+	}
+
+	name := strings.ToLower(env.args[1])
+	content := strings.TrimSpace(strings.Join(env.args[2:], " "))
+	if content == "" || utf8.RuneCountInString(content) > tagMaxLength {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("Tag content is required, and can be up to %d characters long", tagMaxLength),
+		}
+	}
+
+	existing, err := b.repository.GetTag(env.guildID, name)
+	if err != nil && err != repository.ErrRecordNotFound {
+		return err
+	}
+
+	if sub == tagEdit {
+		if existing == nil {
+			return botError{
+				title:   "Tag Not Found",
+				details: fmt.Sprintf("The tag %q does not exist", name),
+			}
+		}
+		existing.Content = content
+		if err := b.repository.UpdateTag(existing); err != nil {
+			return err
+		}
+		return b.replyTagUpdated(env, fmt.Sprintf("The tag `%s` was updated", name))
+	}
+
+	if existing != nil {
+		return botError{
+			title:   "Validation Error",
+			details: fmt.Sprintf("The tag %q already exists, use `%stag %s` to change it", name, env.commandPrefix, tagEdit),
+		}
+	}
+	if err := b.validateNewCommandName(name, env.guildSettings); err != nil {
+		return err
+	}
+
+	tags, err := b.repository.GetTags(env.guildID)
+	if err != nil {
+		return err
+	}
+	if len(tags) >= b.config.Bot.MaxTagsPerGuild {
+		return botError{
+			title:   "Too Many Tags",
+			details: fmt.Sprintf("A server can have up to %d tags, remove some before adding more", b.config.Bot.MaxTagsPerGuild),
+		}
+	}
+
+	err = b.repository.CreateTag(&repository.Tag{
+		GuildID:   env.guildID,
+		Name:      name,
+		Content:   content,
+		Embed:     sub == tagEmbed,
+		CreatedBy: env.user.ID,
+	})
+	if err != nil {
+		return err
+	}
+	return b.replyTagUpdated(env, fmt.Sprintf("The tag `%s` was created, use it with `%s%s`", name, env.commandPrefix, name))
+}
+
+func (b *Bot) handleTagRemove(env *commandEnvironment) error {
+	if len(env.args) < 2 {
+		return botError{
+			title:   "Validation Error",
+			details: "Tag name is required",
+		}
+	}
+
+	name := strings.ToLower(env.args[1])
+	if err := b.repository.DeleteTag(env.guildID, name); err != nil {
+		if err == repository.ErrRecordNotFound {
+			return botError{
+				title:   "Tag Not Found",
+				details: fmt.Sprintf("The tag %q does not exist", name),
+			}
+		}
+		return err
+	}
+	return b.replyTagUpdated(env, fmt.Sprintf("The tag `%s` was removed", name))
+}
+
+func (b *Bot) replyTagUpdated(env *commandEnvironment, description string) error {
+	embed := b.newEmbed()
+	embed.Title = "Update Successful"
+	embed.Description = description
+	embed.Color = 0x00FF00
+	return env.Reply(embed)
+}
@@ -7,11 +7,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (b *Bot) handleTypeCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleTypeCmd(env *commandEnvironment) error {
 
 	if len(env.args) == 0 {
 		return botError{
@@ -20,15 +16,11 @@ func (b *Bot) handleTypeCmd(
 		}
 	}
 
-	pkmType := env.args[0]
-	typeInfo, err := b.repository.PokemonType(strings.ToLower(pkmType))
+	typeInfo, err := b.findPokemonType(env.args[0])
 	if err != nil {
-		return botError{
-			title: "Type not found",
-			details: fmt.Sprintf("Type %s could not be found.",
-				pkmType),
-		}
+		return err
 	}
+	pkmType := typeInfo.Name
 
 	embed := b.newEmbed()
 	embed.Title = fmt.Sprintf("%s Type Info", strings.Title(pkmType))
@@ -50,7 +42,7 @@ func (b *Bot) handleTypeCmd(
 		},
 	}
 
-	return sendEmbed(s, m.ChannelID, embed)
+	return env.Reply(embed)
 }
 
 func generateTypeMessage(typeInfo map[string]float64) string {
@@ -7,11 +7,7 @@ import (
 	"github.com/caquillo07/rotom-bot/metrics"
 )
 
-func (b *Bot) handleVersionCmd(
-	s *discordgo.Session,
-	env *commandEnvironment,
-	m *discordgo.Message,
-) error {
+func (b *Bot) handleVersionCmd(env *commandEnvironment) error {
 
 	const (
 		url = "https://github.com/caquillo07/rotom-b"
@@ -49,6 +45,5 @@ func (b *Bot) handleVersionCmd(
 		},
 	}
 
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	return err
+	return env.Reply(embed)
 }
@@ -1,9 +1,12 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -15,24 +18,99 @@ const (
 )
 
 type command struct {
-	execute  func(s *discordgo.Session, env *commandEnvironment, m *discordgo.Message) error
+	// name is the name the command is registered under, filled in by
+	// initCommands.
+	name string
+
+	execute  func(env *commandEnvironment) error
 	helpText string
 	usage    func(prefix string) string
 	example  func(prefix string) string
 	alias    string
 
+	// options are the typed arguments the command takes when invoked as an
+	// application (slash) command. They are mapped back into the same args
+	// a prefix command gets, in the order they are declared.
+	options []*discordgo.ApplicationCommandOption
+
+	// cooldown is how long a user has to wait before running the command
+	// again, zero means no cooldown.
+	cooldown time.Duration
+
 	// adminOnly is a command that only users with Admin permissions in the server
 	adminOnly bool
 
-	// botAdminOnly is a command that only the bot maintainers can run
-	// TODO(hector): add support for this
+	// botAdminOnly is a command that only the bot maintainers can run, it is
+	// hidden from everyone else.
 	botAdminOnly bool
 }
 
+// commandEnvironment is everything a command needs to know about the request
+// it is handling, and how to respond to it. It is independent of where the
+// command came from, so the same handlers work for prefix commands, slash
+// commands or anything else.
 type commandEnvironment struct {
 	args          []string
 	command       string
 	commandPrefix string
+
+	// reqID is the unique ID of the request, shown to the user on internal
+	// errors so they can be tracked down.
+	reqID uint64
+
+	// user is the user who invoked the command
+	user *discordgo.User
+
+	// guildID and channelID are where the command was invoked from
+	guildID   string
+	channelID string
+
+	// messageID is the message the command was sent on, empty for slash
+	// commands.
+	messageID string
+
+	// guildSettings are the settings of the guild the command was invoked on
+	guildSettings *repository.GuildSettings
+
+	// session is the discord session the command came in through. It may be
+	// nil if the command was not invoked through discord, in which case the
+	// command must not rely on it.
+	session *discordgo.Session
+
+	// ctx is done once the command runs out of time, anything the command
+	// does on its behalf should be abandoned by then.
+	ctx context.Context
+
+	responder Responder
+	bot       *Bot
+}
+
+// Reply sends the given embed as a response to the command.
+func (env *commandEnvironment) Reply(embed *discordgo.MessageEmbed) error {
+	return env.responder.Reply(env.ctx, embed)
+}
+
+// ReplyFile sends the given file as a response to the command, along with an
+// optional embed.
+func (env *commandEnvironment) ReplyFile(name string, r io.Reader, embed *discordgo.MessageEmbed) error {
+	return env.responder.ReplyFile(env.ctx, name, r, embed)
+}
+
+// ReplyText sends the given plain text as a response to the command.
+func (env *commandEnvironment) ReplyText(content string) error {
+	return env.responder.ReplyText(env.ctx, content)
+}
+
+// ReplyError lets the user know the command failed. If the error is a
+// botError, it is considered public and passed on to the user as is, anything
+// else is reported as an internal error.
+func (env *commandEnvironment) ReplyError(err error) error {
+	errTitle := "Internal Error"
+	errDetails := fmt.Sprintf(`Whoops, there was an error processing the request with ID **%d**`, env.reqID)
+	if publicErr, ok := err.(botError); ok {
+		errTitle, errDetails = publicErr.title, publicErr.details
+	}
+	return env.Reply(env.bot.newErrorEmbedf(errTitle, "%s", errDetails))
 }
 
 type pokemonArg struct {
@@ -54,6 +132,10 @@ func (b *Bot) initCommands() {
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}help\n{{p}}help den", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("command", "Command to get detailed help for", false),
+		},
+		cooldown:  3 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["den"] = &command{
@@ -65,6 +147,10 @@ func (b *Bot) initCommands() {
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}den 22\n{{p}}den charizard", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("den", "Den number or Pokémon name", true),
+		},
+		cooldown:  3 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["ball"] = &command{
@@ -76,6 +162,10 @@ func (b *Bot) initCommands() {
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}ball beast", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("ball", "Name of the Poké-Ball", true),
+		},
+		cooldown:  2 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["catch"] = &command{
@@ -92,6 +182,12 @@ The confidence level will display when this calculations fall under the rounding
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}catch charizard gmax lux", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("pokemon", "Name of the Pokémon", true),
+			stringOption("form", "Form of the Pokémon, i.e. gmax", false),
+			stringOption("ball", "Name of the Poké-Ball", false),
+		},
+		cooldown:  3 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["credits"] = &command{
@@ -114,6 +210,10 @@ The confidence level will display when this calculations fall under the rounding
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}nature modest", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("nature", "Name of the nature", false),
+		},
+		cooldown:  2 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["type"] = &command{
@@ -125,6 +225,10 @@ The confidence level will display when this calculations fall under the rounding
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}type grass", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("type", "Name of the type", true),
+		},
+		cooldown:  2 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["pokedex"] = &command{
@@ -136,6 +240,11 @@ The confidence level will display when this calculations fall under the rounding
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}pokedex caterpie", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("pokemon", "Name of the Pokémon, add * for shiny", true),
+			stringOption("form", "Form of the Pokémon, i.e. galarian", false),
+		},
+		cooldown:  3 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["sprite"] = &command{
@@ -147,6 +256,11 @@ The confidence level will display when this calculations fall under the rounding
 		example: func(prefix string) string {
 			return b.addCmdPrefix("{{p}}sprite charizard* gmax", prefix)
 		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("pokemon", "Name of the Pokémon, add * for shiny", true),
+			stringOption("form", "Form of the Pokémon, i.e. gmax", false),
+		},
+		cooldown:  3 * time.Second,
 		adminOnly: false,
 	}
 	b.commands["invite"] = &command{
@@ -178,11 +292,112 @@ The confidence level will display when this calculations fall under the rounding
 			return b.addCmdPrefix("{{p}}settings", prefix)
 		},
 		example: func(prefix string) string {
-			return b.addCmdPrefix("{{p}}settings prefix %, {{p}}settings listen #channel", prefix)
+			return b.addCmdPrefix("{{p}}settings prefix %, {{p}}settings listen #channel, "+
+				"{{p}}settings admin-role add @role, {{p}}settings admin-user remove @user, "+
+				"{{p}}settings command disable sprite #raids, {{p}}settings alias add c catch, "+
+				"{{p}}settings raid-channel #raids, {{p}}settings announcements #bot-news, "+
+				"{{p}}settings timezone America/New_York, {{p}}settings quiet-hours 23:00-07:00, "+
+				"{{p}}settings ignore-role add @muted, {{p}}settings export, {{p}}settings delete", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("setting", "Name of the setting to update", false),
+			stringOption("value", "New value for the setting", false),
 		},
 		adminOnly: true,
 	}
 
+	b.commands["tag"] = &command{
+		execute:  b.handleTagCmd,
+		helpText: "Shows one of the server's tags, or lets admins manage them. Tags can also be used as commands.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}tag <name|list|add|embed|edit|remove> [name] [content]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}tag rules, {{p}}rules, {{p}}tag list, {{p}}tag add rules Be nice!, {{p}}tag embed map https://i.imgur.com/map.png, {{p}}tag remove rules", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("tag", "Tag to show, or one of list, add, embed, edit or remove", true),
+			stringOption("arguments", "Name and content of the tag when managing tags", false),
+		},
+		cooldown:  2 * time.Second,
+		adminOnly: false,
+	}
+	b.commands["fc"] = &command{
+		execute:  b.handleFriendCodeCmd,
+		helpText: "Shows a user's friend code, in game name and game, or lets you manage your own.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}fc [@user|set|visibility|hide|delete] [field] [value]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}fc, {{p}}fc @user, {{p}}fc set code SW-1234-5678-9012, {{p}}fc set ign Ash, {{p}}fc set game sword, {{p}}fc visibility global, {{p}}fc hide, {{p}}fc delete", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("user", "User to look up, or one of set, visibility, hide or delete", false),
+			stringOption("arguments", "Field and value when updating your friend code", false),
+		},
+		cooldown:  2 * time.Second,
+		adminOnly: false,
+	}
+	b.commands["host"] = &command{
+		execute:  b.handleHostCmd,
+		helpText: "Hosts a Max Raid, up to 3 challengers can join by reacting and get the link code by DM.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}host <den|pokemon|close> [code] [gmax] [shiny]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}host 22, {{p}}host toxtricity 1234 5678 gmax, {{p}}host *charizard gmax, {{p}}host close", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("raid", "Den number or Pokémon to host, or close to close your raid", true),
+			stringOption("options", "Link code, gmax and shiny", false),
+		},
+		cooldown:  5 * time.Second,
+		adminOnly: false,
+	}
+	b.commands["raid"] = &command{
+		execute:  b.handleRaidCmd,
+		helpText: "Schedules raids ahead of time, users RSVP by reacting and get pinged before they start.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}raid <schedule|list|cancel> [date] [time] [timezone] [remind:minutes] [title|id]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}raid schedule 8pm EST Gmax Toxtricity, {{p}}raid schedule tomorrow 20:30 remind:30 Shiny Charizard, {{p}}raid list, {{p}}raid cancel 12", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("action", "One of schedule, list or cancel", true),
+			stringOption("arguments", "Start time and title of the raid, or the ID of the raid to cancel", false),
+		},
+		cooldown:  3 * time.Second,
+		adminOnly: false,
+	}
+	b.commands["notify"] = &command{
+		execute:  b.handleNotifyCmd,
+		helpText: "Pings you when someone hosts a raid for a Pokémon you are hunting.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}notify <add|remove|list> [pokemon] [shiny] [gmax]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}notify add toxtricity gmax, {{p}}notify add *charizard, {{p}}notify remove toxtricity gmax, {{p}}notify list", prefix)
+		},
+		options: []*discordgo.ApplicationCommandOption{
+			stringOption("action", "One of add, remove or list", true),
+			stringOption("pokemon", "Pokémon, with shiny and gmax to only get notified of those raids", false),
+		},
+		cooldown:  2 * time.Second,
+		adminOnly: false,
+	}
+	b.commands["maint"] = &command{
+		execute:  b.handleMaintCmd,
+		helpText: "Maintainer tools to operate the bot.",
+		usage: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}maint <reload|stats|guild|maintenance|evict|announce> [argument]", prefix)
+		},
+		example: func(prefix string) string {
+			return b.addCmdPrefix("{{p}}maint stats, {{p}}maint guild 123456789, {{p}}maint maintenance on, {{p}}maint evict, {{p}}maint announce v1.9.0", prefix)
+		},
+		botAdminOnly: true,
+	}
+
 	// Alias for pre-established commands
 	b.commands["commands"] = &command{alias: "help"}
 	b.commands["pokemon"] = &command{alias: "pokedex"}
@@ -191,9 +406,14 @@ The confidence level will display when this calculations fall under the rounding
 	b.commands["config"] = &command{alias: "settings"}
 	b.commands["support"] = &command{alias: "help"}
 	b.commands["image"] = &command{alias: "sprite"}
+	b.commands["friendcode"] = &command{alias: "fc"}
 	b.commands["dens"] = &command{alias: "den"}
 	b.commands["s"] = &command{alias: "sprite"}
 	b.commands["d"] = &command{alias: "den"}
+
+	for name, cmd := range b.commands {
+		cmd.name = name
+	}
 }
 
 // addCmdPrefix replaces all cases of {{p}} with the actual
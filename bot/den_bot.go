@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -8,17 +9,27 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/kubastick/dblgo"
+	"github.com/lib/pq"
+	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/caquillo07/rotom-bot/conf"
+	"github.com/caquillo07/rotom-bot/metrics"
 	"github.com/caquillo07/rotom-bot/repository"
 )
 
-const galarian = "galarian"
+const (
+	galarian = "galarian"
+
+	// deleteConfirmationTimeout is how long a server admin has to confirm the
+	// deletion of the server's data.
+	deleteConfirmationTimeout = time.Minute
+)
 
 // Bot is the struct defining the den bot, it is responsible for listening
 // to on the discord session and handling messages.
@@ -27,14 +38,49 @@ type Bot struct {
 	sessions       []*discordgo.Session
 	repository     *repository.Repository
 	commands       map[string]*command
+	shards         *shardTracker
 	requestsServed uint64
+	startedAt      time.Time
+
+	limiter  *rateLimiter
+	executor *executor
+
+	// pendingDeletions holds the guilds waiting for their data deletion to
+	// be confirmed, and the user who requested it.
+	pendingDeletions *cache.Cache
+
+	// lobbies and schedules track the messages of the open Max Raid lobbies
+	// and the upcoming scheduled raids.
+	lobbies   *trackedMessages
+	schedules *trackedMessages
+
+	// maintenance is set to 1 while the bot is in maintenance mode, in
+	// which only the maintainers can run commands.
+	maintenance int32
+
+	// announcing is set to 1 while an update announcement is being sent
+	announcing int32
+
+	// done is closed once the bot starts shutting down, and background
+	// tracks the work commands leave running, which is waited on before
+	// the sessions are closed.
+	done       chan struct{}
+	background sync.WaitGroup
 }
 
 // NewBot creates a new bot instance from the given session and config
 func NewBot(conf conf.Config) *Bot {
 	return &Bot{
-		config:   conf,
-		commands: make(map[string]*command),
+		config:    conf,
+		commands:  make(map[string]*command),
+		shards:    newShardTracker(),
+		startedAt: time.Now(),
+		limiter:   newRateLimiter(conf.RateLimit.GuildRate, conf.RateLimit.GuildBurst),
+
+		pendingDeletions: cache.New(deleteConfirmationTimeout, 2*deleteConfirmationTimeout),
+		lobbies:          newTrackedMessages(),
+		schedules:        newTrackedMessages(),
+		done:             make(chan struct{}),
 	}
 }
 
@@ -57,8 +103,22 @@ func (b *Bot) Run() error {
 	}
 	b.repository = repo
 
-	// Create all the commands on the bot
+	// Pick up the raid lobbies that were open before the bot stopped
+	if err := b.loadRaidLobbies(); err != nil {
+		return errors.Wrap(err, "failed to load raid lobbies")
+	}
+	if err := b.loadRaidSchedules(); err != nil {
+		return errors.Wrap(err, "failed to load scheduled raids")
+	}
+
+	// Create all the commands on the bot, and the workers to run them
 	b.initCommands()
+	b.executor = newExecutor(b.config.Executor.Workers, b.config.Executor.QueueSize)
+
+	if b.config.HTTP.Enable {
+		httpServer := b.startHTTPServer()
+		defer stopHTTPServer(httpServer)
+	}
 
 	// Create a new Discord session using the provided login information.
 	// This session will help us get the recommended sharding numbers.
@@ -77,12 +137,13 @@ func (b *Bot) Run() error {
 		fmt.Sprintf("opening bot with %d shards", gateway.Shards),
 		zap.Int("shards", gateway.Shards),
 	)
+	b.shards.setCount(gateway.Shards)
 
-	// Now lets create a new session for each of the shards we get.
+	// Now lets create a new session for each of the shards we get. All the
+	// handlers are added before any shard connects, so the events sent
+	// right after connecting, like the initial guild creates, are not missed.
 	b.sessions = make([]*discordgo.Session, gateway.Shards)
-	wg := sync.WaitGroup{}
 	for i := 0; i < gateway.Shards; i++ {
-		logger.Info(fmt.Sprintf("opening shared %d", i+1))
 		session, err := discordgo.New(discordToken)
 		if err != nil {
 			return err
@@ -90,9 +151,17 @@ func (b *Bot) Run() error {
 
 		session.ShardCount = gateway.Shards
 		session.ShardID = i
-		session.Identify.Intents = discordgo.MakeIntent(discordgo.IntentsAllWithoutPrivileged)
+		session.Identify.Intents = discordgo.MakeIntent(
+			discordgo.IntentsAllWithoutPrivileged | discordgo.IntentMessageContent,
+		)
 		session.State.TrackPresences = false
+		b.addHandlers(session)
 		b.sessions[i] = session
+	}
+
+	wg := sync.WaitGroup{}
+	for i, session := range b.sessions {
+		logger.Info(fmt.Sprintf("opening shared %d", i+1))
 		wg.Add(1)
 
 		// We shoot the connection off on its own to speed it up a bit.
@@ -101,29 +170,61 @@ func (b *Bot) Run() error {
 			defer wg.Done()
 			if err := session.Open(); err != nil {
 				logger.Error(fmt.Sprintf("error opening connection on shard %d", shard), zap.Error(err))
+				return
 			}
-		}(b.sessions[i], i+1)
+			b.shards.setConnected(session.ShardID, true)
+		}(session, i+1)
 	}
 	wg.Wait()
 
-	// Add all handlers
-	for _, session := range b.sessions {
-
-		// Register ready as a callback for the ready events.
-		session.AddHandler(b.ready)
-
-		// Register the handleMessage func as a callback for MessageCreate events.
-		session.AddHandler(b.handleMessage)
+	if b.config.Discord.SlashCommands.Enable {
+		if err := b.registerApplicationCommands(mainSession); err != nil {
+			return errors.Wrap(err, "failed to register application commands")
+		}
 	}
 
 	// Wait here until CTRL-C or other term signal is received.
 	logger.Info("Bot is now running.  Press CTRL-C to exit.")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+
+	// SIGHUP reloads the data files, so data fixes don't require a restart.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := b.reloadData(); err != nil {
+				logger.Error("failed to reload data, keeping the current data", zap.Error(err))
+			}
+		}
+	}()
+
+	// Delete the data of the guilds the bot was removed from once their
+	// retention is over.
+	done := b.done
+	if b.config.Bot.LeftGuildRetentionDays > 0 {
+		go b.cleanUpLeftGuilds(done)
+	}
+	go b.closeExpiredRaidLobbies(done)
+	go b.runRaidScheduler(done)
+
 	<-sc
+	signal.Stop(hup)
+	close(done)
 
-	// Cleanly close down the Discord session.
+	// Stop taking new commands, and give the ones in flight a chance to
+	// reply before closing the sessions.
 	logger.Info("Shutting down...")
+	drainCtx, cancel := context.WithTimeout(context.Background(), b.config.Executor.ShutdownTimeout)
+	defer cancel()
+	if err := b.executor.shutdown(drainCtx); err != nil {
+		logger.Warn("timed out waiting for running commands to finish", zap.Error(err))
+	}
+	if err := b.waitBackground(drainCtx); err != nil {
+		logger.Warn("timed out waiting for background work to finish", zap.Error(err))
+	}
+
+	// Cleanly close down the Discord session.
 	var closingError error
 	for i, session := range b.sessions {
 
@@ -134,13 +235,72 @@ func (b *Bot) Run() error {
 	return closingError
 }
 
+// waitBackground waits for the background work to stop, until the context is
+// done.
+func (b *Bot) waitBackground(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		b.background.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// addHandlers registers all the event handlers on the session.
+func (b *Bot) addHandlers(session *discordgo.Session) {
+
+	// Register ready as a callback for the ready events.
+	session.AddHandler(b.ready)
+
+	// Register the handleMessage func as a callback for MessageCreate events.
+	session.AddHandler(b.handleMessage)
+
+	// Register the handleInteraction func as a callback for
+	// InteractionCreate events, this is how slash commands come in.
+	session.AddHandler(b.handleInteraction)
+
+	// Keep the guild settings in sync with the guilds the bot is on.
+	session.AddHandler(b.guildCreate)
+	session.AddHandler(b.guildDelete)
+	session.AddHandler(b.guildUpdate)
+	session.AddHandler(b.channelUpdate)
+	session.AddHandler(b.channelDelete)
+
+	// Challengers join and leave raid lobbies, and RSVP to scheduled
+	// raids, by reacting to them.
+	session.AddHandler(b.raidReactionAdd)
+	session.AddHandler(b.raidReactionRemove)
+	session.AddHandler(b.raidScheduleReactionAdd)
+	session.AddHandler(b.raidScheduleReactionRemove)
+
+	// Keep track of the shards connection state.
+	session.AddHandler(b.shardConnected)
+	session.AddHandler(b.shardDisconnected)
+}
+
+// reloadData loads the data files again and swaps them in for every shard. If
+// the new data is not valid, the current data is kept.
+func (b *Bot) reloadData() error {
+	if err := b.repository.ReloadData(); err != nil {
+		return err
+	}
+	zap.L().Info("data reloaded", zap.Time("loaded_at", b.repository.DataLoadedAt()))
+	return nil
+}
+
 // This function will be called (due to AddHandler above) when the bot receives
 // the "ready" event from Discord.
 func (b *Bot) ready(s *discordgo.Session, event *discordgo.Ready) {
 	zap.L().Info("Bot is ready!")
 
 	// Set the playing status.
-	if err := s.UpdateStatus(0, b.config.Discord.PlayingStatus); err != nil {
+	if err := s.UpdateGameStatus(0, b.config.Discord.PlayingStatus); err != nil {
 		zap.L().Error("error setting bot playing status", zap.Error(err))
 	}
 
@@ -156,6 +316,18 @@ func (b *Bot) ready(s *discordgo.Session, event *discordgo.Ready) {
 	}
 }
 
+// shardConnected is called every time a shard (re)connects to the gateway.
+func (b *Bot) shardConnected(s *discordgo.Session, _ *discordgo.Connect) {
+	b.shards.setConnected(s.ShardID, true)
+}
+
+// shardDisconnected is called every time a shard loses its connection to the
+// gateway.
+func (b *Bot) shardDisconnected(s *discordgo.Session, _ *discordgo.Disconnect) {
+	zap.L().Warn("shard disconnected", zap.Int("shard", s.ShardID))
+	b.shards.setConnected(s.ShardID, false)
+}
+
 // This function will be called (due to AddHandler above) every time a new
 // message is created on any channel that the authenticated bot has access to.
 func (b *Bot) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -171,44 +343,54 @@ func (b *Bot) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	logger := zap.L()
 	channel, err := s.State.Channel(m.ChannelID)
 	if err != nil {
-		logger.Error(
-			"error retrieving the channel",
-			zap.Error(err),
-			zap.String("channel_id", channel.ID),
-		)
-		return
-	}
-
-	guild, err := s.State.Guild(channel.GuildID)
-	if err != nil {
-		logger.Error(
-			"error retrieving the guild",
-			zap.Error(err),
-			zap.String("guild_id", channel.GuildID),
-		)
-		return
+		// DM channels are not always in the state, ask discord for it.
+		if channel, err = s.Channel(m.ChannelID); err != nil {
+			logger.Error(
+				"error retrieving the channel",
+				zap.Error(err),
+				zap.String("channel_id", m.ChannelID),
+			)
+			return
+		}
 	}
 
-	guildSettings, err := b.getOrCreateGuildSettings(guild)
-	if err != nil {
-		logger.Error(
-			"error retrieving the guild's config",
-			zap.Error(err),
-			zap.String("guild_name", guild.Name),
-			zap.String("guild_id", guild.ID),
-		)
-	}
+	// Direct messages have no guild, they use the default settings.
+	guild := &discordgo.Guild{Name: "DM"}
+	guildSettings := b.defaultGuildSettings()
+	if m.GuildID != "" {
+		guild, err = s.State.Guild(m.GuildID)
+		if err != nil {
+			logger.Error(
+				"error retrieving the guild",
+				zap.Error(err),
+				zap.String("guild_id", m.GuildID),
+			)
+			return
+		}
 
-	prefix := b.config.Bot.Prefix
-	if pre := guildSettings.BotPrefix; pre != "" {
-		prefix = pre
+		guildSettings, err = b.getOrCreateGuildSettings(guild)
+		if err != nil {
+			logger.Error(
+				"error retrieving the guild's config",
+				zap.Error(err),
+				zap.String("guild_name", guild.Name),
+				zap.String("guild_id", guild.ID),
+			)
+			return
+		}
 	}
 
 	// If the message does not have the required prefix, exit as well
-	if !strings.HasPrefix(m.Content, prefix) {
+	cleanedMsg, ok := b.trimCommandPrefix(m.Content, s.State.User.ID, guildSettings)
+	if !ok {
 		return
 	}
 
+	// just mentioning the bot shows the help, which includes the prefix.
+	if cleanedMsg == "" {
+		cleanedMsg = "help"
+	}
+
 	// If this guild has specific channels to listen on and this channel is not
 	// in it, exit.
 	if len(guildSettings.ListeningChannels) > 0 && !inListenChannels(channel.ID, guildSettings.ListeningChannels) {
@@ -217,6 +399,7 @@ func (b *Bot) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 	// Update the requests served, so we can get new ID for the next request
 	reqID := atomic.AddUint64(&b.requestsServed, 1)
+	metrics.RequestsServed.Inc()
 	logger.Info(
 		"processing command",
 		zap.Uint64("request_id", reqID),
@@ -226,87 +409,255 @@ func (b *Bot) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 		zap.String("guild_name", guild.Name),
 	)
 
+	env := &commandEnvironment{
+		commandPrefix: b.mainPrefix(guildSettings),
+		reqID:         reqID,
+		user:          m.Author,
+		guildID:       guild.ID,
+		channelID:     channel.ID,
+		messageID:     m.ID,
+		guildSettings: guildSettings,
+		session:       s,
+		responder:     &channelResponder{session: s, channelID: channel.ID},
+		ctx:           context.Background(),
+		bot:           b,
+	}
+
 	// Handle panics gracefully, sucks that we do it this late but we need some
 	// of the info gathered above. Special care needed when changing code above.
-	defer func(reqID uint64) {
+	defer func() {
 		if r := recover(); r != nil {
-			b.handlePanic(r, s, m, reqID)
+			b.handlePanic(r, env)
 		}
-	}(reqID)
+	}()
 
-	// To not have to check for the prefix on every single command
-	cleanedMsg := strings.TrimPrefix(m.Content, prefix)
+	// Command names are matched regardless of their case
 	cmdParts := strings.Split(cleanedMsg, " ")
-	botCmd, ok := b.commands[cmdParts[0]]
+	cmdParts[0] = strings.ToLower(cmdParts[0])
+	botCmd, ok := b.resolveCommand(cmdParts[0], guildSettings)
 	if !ok {
-		// ignoring unknown commands
-		return
-	}
-	if botCmd.alias != "" {
-		botCmd, ok = b.commands[botCmd.alias]
-		if !ok {
+		// unknown commands may be one of the guild's tags, anything else is
+		// ignored.
+		if !b.isTag(guild.ID, cmdParts[0]) {
 			return
 		}
+		botCmd = b.commands["tag"]
+		cmdParts = []string{cmdParts[0], cmdParts[0]}
 	}
 
-	// Send this off on its own go routine to be able to handle many of them
-	// at once
-	go func(reqID uint64) {
-		// before anything else, lets make sure the user has permissions
-		// to perform this command
-		if botCmd.adminOnly {
-			isAdmin, err := userIsAdmin(s, guild.ID, m.Author.ID)
-			if err != nil {
-				logger.Error(
-					"failed to check user's admin status",
-					zap.String("command", cleanedMsg),
-					zap.Uint64("request_id", reqID),
-					zap.Error(err),
-				)
-
-				b.handleCommandError(s, m, reqID, err)
-				return
-			}
-			if !isAdmin {
-				logger.Info(
-					m.Author.String()+" user doesn't have admin role",
-					zap.String("command", cleanedMsg),
-					zap.String("user", m.Author.String()),
-				)
-				return
-			}
+	// maintainer commands are treated as unknown commands for everyone else
+	if botCmd.botAdminOnly && !b.isMaintainer(m.Author.ID) {
+		return
+	}
+
+	// so are the commands the guild disabled on this channel
+	if commandDisabled(guildSettings, cmdParts[0], botCmd, channel.ID) {
+		return
+	}
+
+	var memberRoles []string
+	if m.Member != nil {
+		memberRoles = m.Member.Roles
+	}
+	if isIgnored(guildSettings, botCmd, channel.ID, m.Author.ID, memberRoles) {
+		return
+	}
+	env.command = cmdParts[0]
+	env.args = cmdParts[1:]
+
+	if wait, ok := b.allowCommand(botCmd, env); !ok {
+		if b.limiter.shouldWarn(botCmd, env.user.ID, wait) {
+			go sendTemporaryEmbed(s, channel.ID, b.slowDownEmbed(wait), slowDownMessageLifetime)
 		}
+		return
+	}
 
-		env := &commandEnvironment{
-			args:          cmdParts[1:],
-			command:       cmdParts[0],
-			commandPrefix: prefix,
+	// Send this off to the workers to be able to handle many of them at once
+	b.dispatch(botCmd, env)
+}
+
+// runCommand checks the user has the permissions needed for the given
+// command, and executes it. Any errors are communicated back to the user.
+func (b *Bot) runCommand(botCmd *command, env *commandEnvironment) {
+	logger := zap.L()
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout := b.config.Executor.CommandTimeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+	env.ctx = ctx
+
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomePanic).Inc()
+			b.handlePanic(r, env)
 		}
-		if err := botCmd.execute(s, env, m.Message); err != nil {
+	}()
+
+	// while in maintenance mode, only the maintainers can use the bot
+	if b.inMaintenance() && !b.isMaintainer(env.user.ID) {
+		metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeDenied).Inc()
+		b.handleCommandError(env, botError{
+			title:   "Under Maintenance",
+			details: "Rotom-B is undergoing maintenance, please try again in a few minutes.",
+		})
+		return
+	}
+
+	// admin commands change the guild's settings, there is nothing to change
+	// in a direct message.
+	if botCmd.adminOnly && env.guildID == "" {
+		metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeDenied).Inc()
+		b.handleCommandError(env, botError{
+			title:   "Not Available In DMs",
+			details: fmt.Sprintf("The %s command can only be used in a server", env.command),
+		})
+		return
+	}
+
+	// before anything else, lets make sure the user has permissions
+	// to perform this command
+	if botCmd.adminOnly {
+		isAdmin, err := userIsAdmin(env.session, env.guildSettings, env.guildID, env.user.ID)
+		if err != nil {
 			logger.Error(
-				"failed to handle command",
-				zap.String("command", cleanedMsg),
-				zap.Uint64("request_id", reqID),
+				"failed to check user's admin status",
+				zap.String("command", env.command),
+				zap.Uint64("request_id", env.reqID),
 				zap.Error(err),
 			)
 
-			b.handleCommandError(s, m, reqID, err)
+			metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeInternalError).Inc()
+			b.handleCommandError(env, err)
+			return
+		}
+		if !isAdmin {
+			metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeDenied).Inc()
+			logger.Info(
+				env.user.String()+" user doesn't have admin role",
+				zap.String("command", env.command),
+				zap.String("user", env.user.String()),
+			)
+
+			// prefix commands are ignored as before, but slash commands were
+			// already acknowledged and need an answer.
+			if r, ok := env.responder.(*interactionResponder); ok {
+				embed := b.newErrorEmbedf("Permission Denied", "Only the server's bot admins can use the %s command", env.command)
+				if err := r.replyEphemeral(env.ctx, embed); err != nil {
+					logger.Error("failed to respond to interaction", zap.Uint64("request_id", env.reqID), zap.Error(err))
+				}
+			}
+			return
 		}
-	}(reqID)
+	}
+
+	start := time.Now()
+	err := botCmd.execute(env)
+	metrics.CommandDuration.WithLabelValues(botCmd.name).Observe(time.Since(start).Seconds())
+
+	// the command ran out of time, anything it tried to send was abandoned
+	// so give the error its own context to let the user know.
+	if ctx.Err() == context.DeadlineExceeded {
+		metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeTimeout).Inc()
+		env.ctx = context.Background()
+		logger.Error(
+			"command timed out",
+			zap.String("command", env.command),
+			zap.Strings("args", env.args),
+			zap.Uint64("request_id", env.reqID),
+			zap.NamedError("command_error", err),
+		)
+		b.handleCommandError(env, botError{
+			title:   "Timed Out",
+			details: fmt.Sprintf("The request with ID **%d** took too long, please try again", env.reqID),
+		})
+		return
+	}
+
+	metrics.CommandsExecuted.WithLabelValues(botCmd.name, commandOutcome(err)).Inc()
+	if err != nil {
+		logger.Error(
+			"failed to handle command",
+			zap.String("command", env.command),
+			zap.Strings("args", env.args),
+			zap.Uint64("request_id", env.reqID),
+			zap.Error(err),
+		)
+
+		b.handleCommandError(env, err)
+	}
 }
 
-func (b *Bot) handleCommandError(s *discordgo.Session, m *discordgo.MessageCreate, reqID uint64, err error) {
-	// If the error is a botError, that means that we will consider
-	// it public and just pass the error on to the user.
-	errTitle := "Internal Error"
-	errDetails := fmt.Sprintf(`Whoops, there was an error processing the request with ID **%d**`, reqID)
-	if publicErr, ok := err.(botError); ok {
-		errTitle, errDetails = publicErr.title, publicErr.details
+// dispatch queues the command to be run by the executor. If it can't take
+// any more commands, the user is told to try again later.
+func (b *Bot) dispatch(botCmd *command, env *commandEnvironment) {
+	err := b.executor.submit(func() { b.runCommand(botCmd, env) })
+	if err == nil {
+		return
 	}
-	_, err = s.ChannelMessageSendEmbed(m.ChannelID, b.newErrorEmbedf(errTitle, errDetails))
+
+	metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeRejected).Inc()
+	zap.L().Warn(
+		"command rejected",
+		zap.String("command", env.command),
+		zap.Uint64("request_id", env.reqID),
+		zap.Error(err),
+	)
+
+	publicErr := botError{
+		title:   "Too Busy",
+		details: "Rotom-B is handling too many commands right now, please try again in a moment",
+	}
+	if err == errExecutorClosed {
+		publicErr = botError{
+			title:   "Restarting",
+			details: "Rotom-B is restarting, please try again in a minute",
+		}
+	}
+	b.handleCommandError(env, publicErr)
+}
+
+// allowCommand checks the command is not over its cooldown or the guild's
+// limits. Maintainers are never limited.
+func (b *Bot) allowCommand(botCmd *command, env *commandEnvironment) (time.Duration, bool) {
+	if b.isMaintainer(env.user.ID) {
+		return 0, true
+	}
+
+	wait, ok := b.limiter.allow(botCmd, env.guildID, env.user.ID)
+	if !ok {
+		metrics.CommandsExecuted.WithLabelValues(botCmd.name, metrics.OutcomeRateLimited).Inc()
+		zap.L().Info(
+			"command rate limited",
+			zap.String("command", env.command),
+			zap.String("user", env.user.String()),
+			zap.String("guild_id", env.guildID),
+			zap.Duration("wait", wait),
+		)
+	}
+	return wait, ok
+}
+
+// commandOutcome returns the outcome of a command given the error it returned
+func commandOutcome(err error) string {
+	if err == nil {
+		return metrics.OutcomeSuccess
+	}
+	if _, ok := err.(botError); ok {
+		return metrics.OutcomeBotError
+	}
+	return metrics.OutcomeInternalError
+}
+
+func (b *Bot) handleCommandError(env *commandEnvironment, err error) {
+	errorType := metrics.ErrorTypeInternal
+	if _, ok := err.(botError); ok {
+		errorType = metrics.ErrorTypeBot
+	}
+	metrics.CommandErrors.WithLabelValues(errorType).Inc()
 
 	// If this errors, then ¯\_(ツ)_/¯ log and move on
-	if err != nil {
+	if err := env.ReplyError(err); err != nil {
 		zap.L().Error(
 			"failed to communicate command error",
 			zap.Error(err),
@@ -314,13 +665,15 @@ func (b *Bot) handleCommandError(s *discordgo.Session, m *discordgo.MessageCreat
 	}
 }
 
-func (b *Bot) handlePanic(panic interface{}, s *discordgo.Session, m *discordgo.MessageCreate, reqID uint64) {
+func (b *Bot) handlePanic(panic interface{}, env *commandEnvironment) {
+	metrics.PanicsRecovered.Inc()
 	logger := zap.L()
 	logger.Error(
 		"recovered from panic while handling message",
 		zap.String("panic_message", fmt.Sprintf("%s", panic)),
-		zap.String("command", m.Content),
-		zap.String("user", m.Author.Username),
+		zap.String("command", env.command),
+		zap.Strings("args", env.args),
+		zap.String("user", env.user.Username),
 	)
 
 	// Log the stacktrace to the console
@@ -330,7 +683,16 @@ func (b *Bot) handlePanic(panic interface{}, s *discordgo.Session, m *discordgo.
 		zap.Stack("stack_trace"),
 	)
 
-	b.handleCommandError(s, m, reqID, errors.New("internal error"))
+	b.handleCommandError(env, errors.New("internal error"))
+}
+
+// defaultGuildSettings returns the settings used where there are no guild
+// settings, such as direct messages. They are never saved.
+func (b *Bot) defaultGuildSettings() *repository.GuildSettings {
+	return &repository.GuildSettings{
+		Name:        "Default",
+		BotPrefixes: pq.StringArray{b.config.Bot.Prefix},
+	}
 }
 
 func (b *Bot) getOrCreateGuildSettings(guild *discordgo.Guild) (*repository.GuildSettings, error) {
@@ -340,28 +702,14 @@ func (b *Bot) getOrCreateGuildSettings(guild *discordgo.Guild) (*repository.Guil
 	}
 
 	if gc != nil {
-		// TODO: todo so its highlighted, remove this once this is no longer a
-		//  thing. If the name is <replace>, replace it with the real name.
-		//  Since this is a temp action, ignore the error, just log it
-		if gc.Name == "<replace>" {
-			gc.Name = guild.Name
-			if err := b.repository.UpdateGuildSettings(gc); err != nil {
-				zap.L().Error(
-					"failed to update guild name",
-					zap.Error(err),
-					zap.String("guild_id", guild.ID),
-					zap.String("guild_name", guild.Name),
-				)
-			}
-		}
 		return gc, nil
 	}
 
 	// not found, then create
 	gc = &repository.GuildSettings{
-		Name:      guild.Name,
-		DiscordID: guild.ID,
-		BotPrefix: b.config.Bot.Prefix,
+		Name:        guild.Name,
+		DiscordID:   guild.ID,
+		BotPrefixes: pq.StringArray{b.config.Bot.Prefix},
 	}
 	if err := b.repository.CreateGuildSettings(gc); err != nil {
 		return nil, err
@@ -369,10 +717,18 @@ func (b *Bot) getOrCreateGuildSettings(guild *discordgo.Guild) (*repository.Guil
 	return gc, nil
 }
 
+// userIsAdmin checks whether the user can run admin only commands on the
+// guild, either because they have the Administrator permission, or because
+// they, or one of their roles, are listed as bot admins in the settings.
 func userIsAdmin(
 	s *discordgo.Session,
+	settings *repository.GuildSettings,
 	guildID, userID string,
 ) (bool, error) {
+	if contains(settings.BotAdminUsers, userID) {
+		return true, nil
+	}
+
 	member, err := s.State.Member(guildID, userID)
 	if err != nil {
 		// ok to ignore this error since its just a not found error on the
@@ -383,6 +739,10 @@ func userIsAdmin(
 	}
 
 	for _, roleID := range member.Roles {
+		if contains(settings.BotAdminRoles, roleID) {
+			return true, nil
+		}
+
 		role, err := s.State.Role(guildID, roleID)
 		if err != nil {
 			return false, err
@@ -394,9 +754,57 @@ func userIsAdmin(
 	return false, nil
 }
 
-func sendEmbed(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed) error {
-	_, err := s.ChannelMessageSendEmbed(channelID, embed)
-	return err
+// isTag checks whether the guild has a tag with the given name.
+func (b *Bot) isTag(guildID, name string) bool {
+	if guildID == "" {
+		return false
+	}
+
+	_, err := b.repository.GetTag(guildID, name)
+	if err != nil && err != repository.ErrRecordNotFound {
+		zap.L().Error("failed to get tag", zap.Error(err), zap.String("guild_id", guildID))
+	}
+	return err == nil
+}
+
+// isMaintainer checks whether the user is one of the bot maintainers.
+func (b *Bot) isMaintainer(userID string) bool {
+	return contains(b.config.Bot.Maintainers, userID)
+}
+
+func (b *Bot) inMaintenance() bool {
+	return atomic.LoadInt32(&b.maintenance) == 1
+}
+
+func (b *Bot) setMaintenance(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&b.maintenance, value)
+}
+
+// isIgnored checks whether the guild asked the bot to ignore the channel, the
+// user, or any of the user's roles. Admin commands are never ignored, so
+// admins can always change the settings back.
+func isIgnored(
+	settings *repository.GuildSettings,
+	botCmd *command,
+	channelID, userID string,
+	roles []string,
+) bool {
+	if botCmd.adminOnly {
+		return false
+	}
+	if inListenChannels(channelID, settings.IgnoredChannels) || contains(settings.IgnoredUsers, userID) {
+		return true
+	}
+	for _, role := range roles {
+		if contains(settings.IgnoredRoles, role) {
+			return true
+		}
+	}
+	return false
 }
 
 func inListenChannels(id string, s []*repository.GuildSettingChannel) bool {
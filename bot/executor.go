@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errQueueFull      = errors.New("command queue is full")
+	errExecutorClosed = errors.New("executor is shut down")
+)
+
+// executor runs the commands on a fixed number of workers, with a bounded
+// queue in front of them. This keeps a flood of commands from spawning an
+// unbounded number of goroutines, and lets the bot wait for the commands in
+// flight when shutting down.
+type executor struct {
+	jobs    chan func()
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newExecutor(workers, queueSize int) *executor {
+	e := &executor{jobs: make(chan func(), queueSize)}
+	e.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer e.workers.Done()
+			for job := range e.jobs {
+				job()
+			}
+		}()
+	}
+	return e
+}
+
+// submit queues the job to be run by the next free worker. It returns an
+// error without blocking if the queue is full, or the executor was shut down.
+func (e *executor) submit(job func()) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return errExecutorClosed
+	}
+
+	select {
+	case e.jobs <- job:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// shutdown stops accepting new jobs, and waits for the queued and running
+// ones to finish until the context is done.
+func (e *executor) shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.jobs)
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
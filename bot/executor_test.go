@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExecutorSubmit(t *testing.T) {
+	tests := []struct {
+		name      string
+		queueSize int
+		jobs      int
+		wantErrs  int
+	}{
+		{name: "fits in the queue", queueSize: 3, jobs: 3},
+		{name: "queue full", queueSize: 2, jobs: 4, wantErrs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newExecutor(1, tt.queueSize)
+
+			// keep the only worker busy, so the rest of the jobs stay queued
+			started, release := make(chan struct{}), make(chan struct{})
+			if err := e.submit(func() { close(started); <-release }); err != nil {
+				t.Fatalf("submit() error = %v", err)
+			}
+			<-started
+
+			var errs int
+			for i := 0; i < tt.jobs; i++ {
+				if err := e.submit(func() {}); err != nil {
+					if err != errQueueFull {
+						t.Fatalf("submit() error = %v, want %v", err, errQueueFull)
+					}
+					errs++
+				}
+			}
+			close(release)
+			if errs != tt.wantErrs {
+				t.Errorf("submit() failed %d times, want %d", errs, tt.wantErrs)
+			}
+			if err := e.shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestExecutorShutdownDrains(t *testing.T) {
+	e := newExecutor(2, 10)
+
+	var ran int32
+	for i := 0; i < 10; i++ {
+		err := e.submit(func() {
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&ran, 1)
+		})
+		if err != nil {
+			t.Fatalf("submit() error = %v", err)
+		}
+	}
+
+	if err := e.shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&ran); got != 10 {
+		t.Errorf("shutdown() returned after %d jobs, want 10", got)
+	}
+	if err := e.submit(func() {}); err != errExecutorClosed {
+		t.Errorf("submit() after shutdown error = %v, want %v", err, errExecutorClosed)
+	}
+
+	// shutting down twice is fine
+	if err := e.shutdown(context.Background()); err != nil {
+		t.Errorf("second shutdown() error = %v", err)
+	}
+}
+
+func TestExecutorShutdownTimeout(t *testing.T) {
+	e := newExecutor(1, 1)
+	release := make(chan struct{})
+	defer close(release)
+	if err := e.submit(func() { <-release }); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := e.shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
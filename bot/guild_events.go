@@ -0,0 +1,304 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	// leftGuildsCleanupInterval is how often the data of the guilds the bot
+	// was removed from is checked for deletion.
+	leftGuildsCleanupInterval = time.Hour
+
+	// recentlyJoinedWindow is how recently the bot must have joined a guild
+	// for a GuildCreate event to count as a new join. Connecting a shard
+	// sends a GuildCreate for every guild the bot is already on.
+	recentlyJoinedWindow = 5 * time.Minute
+)
+
+// This function will be called (due to AddHandler above) every time the bot
+// joins a guild, and for every guild the bot is on when a shard connects.
+func (b *Bot) guildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
+	if event.Unavailable {
+		return
+	}
+
+	logger := zap.L().With(zap.String("guild_id", event.ID), zap.String("guild_name", event.Name))
+	settings, err := b.repository.GetGuildSettings(event.ID)
+	if err != nil && err != repository.ErrRecordNotFound {
+		logger.Error("failed to get guild settings", zap.Error(err))
+		return
+	}
+
+	justJoined := time.Since(event.JoinedAt) < recentlyJoinedWindow
+
+	// a brand new guild, create its settings and let them know how to set
+	// up the bot.
+	if settings == nil {
+		if _, err := b.getOrCreateGuildSettings(event.Guild); err != nil {
+			logger.Error("failed to create guild settings", zap.Error(err))
+			return
+		}
+		if justJoined {
+			logger.Info("joined guild")
+			b.sendWelcomeMessage(s, event.Guild)
+		}
+		return
+	}
+
+	// otherwise bring the settings up to date, the guild may have changed
+	// while the bot was away.
+	changed := settings.Name != event.Name
+	settings.Name = event.Name
+	names := make(map[string]string, len(event.Channels))
+	for _, c := range event.Channels {
+		names[c.ID] = c.Name
+	}
+	for _, channels := range []*repository.GuildSettingChannels{&settings.ListeningChannels, &settings.IgnoredChannels} {
+		if syncChannels(channels, names) {
+			changed = true
+		}
+	}
+
+	rejoined := settings.LeftAt != nil
+	if rejoined {
+		settings.LeftAt = nil
+		changed = true
+	}
+
+	if changed {
+		if err := b.repository.UpdateGuildSettings(settings); err != nil {
+			logger.Error("failed to update guild settings", zap.Error(err))
+			return
+		}
+	}
+	if rejoined && justJoined {
+		logger.Info("joined guild again")
+		b.sendWelcomeMessage(s, event.Guild)
+	}
+}
+
+// This function will be called (due to AddHandler above) every time the bot
+// is removed from a guild, or the guild becomes unavailable.
+func (b *Bot) guildDelete(_ *discordgo.Session, event *discordgo.GuildDelete) {
+	// unavailable guilds are having an outage, the bot is still on them.
+	if event.Unavailable {
+		return
+	}
+
+	logger := zap.L().With(zap.String("guild_id", event.ID))
+	settings, err := b.repository.GetGuildSettings(event.ID)
+	if err != nil {
+		if err != repository.ErrRecordNotFound {
+			logger.Error("failed to get guild settings", zap.Error(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	settings.LeftAt = &now
+	if err := b.repository.UpdateGuildSettings(settings); err != nil {
+		logger.Error("failed to mark guild as left", zap.Error(err))
+		return
+	}
+	logger.Info("left guild", zap.String("guild_name", settings.Name))
+}
+
+// This function will be called (due to AddHandler above) every time a guild
+// is updated, we only care about renames.
+func (b *Bot) guildUpdate(_ *discordgo.Session, event *discordgo.GuildUpdate) {
+	b.updateGuildSettings(event.ID, func(settings *repository.GuildSettings) bool {
+		if settings.Name == event.Name {
+			return false
+		}
+		settings.Name = event.Name
+		return true
+	})
+}
+
+// This function will be called (due to AddHandler above) every time a channel
+// is updated, to keep the listening and ignored channel names up to date.
+func (b *Bot) channelUpdate(_ *discordgo.Session, event *discordgo.ChannelUpdate) {
+	if event.GuildID == "" {
+		return
+	}
+	b.updateGuildSettings(event.GuildID, func(settings *repository.GuildSettings) bool {
+		changed := false
+		for _, channels := range []repository.GuildSettingChannels{settings.ListeningChannels, settings.IgnoredChannels} {
+			for _, c := range channels {
+				if c.ID == event.ID && c.Name != event.Name {
+					c.Name = event.Name
+					changed = true
+				}
+			}
+		}
+		return changed
+	})
+}
+
+// This function will be called (due to AddHandler above) every time a channel
+// is deleted, to remove it from the listening and ignored channels.
+func (b *Bot) channelDelete(_ *discordgo.Session, event *discordgo.ChannelDelete) {
+	if event.GuildID == "" {
+		return
+	}
+	b.updateGuildSettings(event.GuildID, func(settings *repository.GuildSettings) bool {
+		changed := false
+		for _, channels := range []*repository.GuildSettingChannels{&settings.ListeningChannels, &settings.IgnoredChannels} {
+			remaining := make(repository.GuildSettingChannels, 0, len(*channels))
+			for _, c := range *channels {
+				if c.ID != event.ID {
+					remaining = append(remaining, c)
+				}
+			}
+			if len(remaining) != len(*channels) {
+				*channels = remaining
+				changed = true
+			}
+		}
+		if settings.RaidChannelID == event.ID {
+			settings.RaidChannelID = ""
+			changed = true
+		}
+		if settings.AnnouncementsChannelID == event.ID {
+			settings.AnnouncementsChannelID = ""
+			changed = true
+		}
+		return changed
+	})
+}
+
+// updateGuildSettings applies the update to the guild's settings, and saves
+// them if the update func reports a change. Guilds without settings are
+// ignored.
+func (b *Bot) updateGuildSettings(guildID string, update func(settings *repository.GuildSettings) bool) {
+	logger := zap.L().With(zap.String("guild_id", guildID))
+	settings, err := b.repository.GetGuildSettings(guildID)
+	if err != nil {
+		if err != repository.ErrRecordNotFound {
+			logger.Error("failed to get guild settings", zap.Error(err))
+		}
+		return
+	}
+
+	if !update(settings) {
+		return
+	}
+	if err := b.repository.UpdateGuildSettings(settings); err != nil {
+		logger.Error("failed to update guild settings", zap.Error(err))
+	}
+}
+
+// syncChannels renames the channels to match the given guild channel names,
+// keyed by channel ID, and removes the ones that no longer exist. It reports
+// whether anything changed.
+func syncChannels(channels *repository.GuildSettingChannels, names map[string]string) bool {
+	if len(*channels) == 0 {
+		return false
+	}
+
+	changed := false
+	remaining := make(repository.GuildSettingChannels, 0, len(*channels))
+	for _, c := range *channels {
+		name, ok := names[c.ID]
+		if !ok {
+			changed = true
+			continue
+		}
+		if c.Name != name {
+			c.Name = name
+			changed = true
+		}
+		remaining = append(remaining, c)
+	}
+	*channels = remaining
+	return changed
+}
+
+// sendWelcomeMessage lets the guild know how to set up the bot, on the
+// guild's system channel. Guilds without a system channel are skipped.
+func (b *Bot) sendWelcomeMessage(s *discordgo.Session, guild *discordgo.Guild) {
+	if !b.config.Bot.WelcomeMessage || guild.SystemChannelID == "" {
+		return
+	}
+
+	prefix := b.config.Bot.Prefix
+	embed := b.newEmbed()
+	embed.Title = "Thanks for inviting " + b.config.Bot.Name + "!"
+	embed.Description = fmt.Sprintf(
+		"Use `%shelp` to see everything I can do.",
+		prefix,
+	)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Setup",
+			Value:  "Server administrators can customize the bot with the `" + prefix + "settings` command",
+			Inline: false,
+		},
+		{
+			Name:   "Change the prefix",
+			Value:  "`" + prefix + "settings prefix !`",
+			Inline: false,
+		},
+		{
+			Name:   "Only listen on some channels",
+			Value:  "`" + prefix + "settings listen #channel`",
+			Inline: false,
+		},
+		{
+			Name:   "Let other members manage the bot",
+			Value:  "`" + prefix + "settings admin-role add @role`",
+			Inline: false,
+		},
+		{
+			Name:   "Get the bot's update announcements",
+			Value:  "`" + prefix + "settings announcements #channel`",
+			Inline: false,
+		},
+		{
+			Name:   "Need help?",
+			Value:  fmt.Sprintf("[Join Rotom-B's support server!](%s)", b.config.Discord.SupportServerURL),
+			Inline: false,
+		},
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(guild.SystemChannelID, embed); err != nil {
+		zap.L().Warn(
+			"failed to send welcome message",
+			zap.Error(err),
+			zap.String("guild_id", guild.ID),
+		)
+	}
+}
+
+// cleanUpLeftGuilds deletes the data of every guild the bot was removed from
+// longer than the configured retention ago, until the done channel is closed.
+func (b *Bot) cleanUpLeftGuilds(done <-chan struct{}) {
+	retention := time.Duration(b.config.Bot.LeftGuildRetentionDays) * 24 * time.Hour
+	ticker := time.NewTicker(leftGuildsCleanupInterval)
+	defer ticker.Stop()
+	for {
+		guildIDs, err := b.repository.GuildsLeftBefore(time.Now().Add(-retention))
+		if err != nil {
+			zap.L().Error("failed to find guilds to clean up", zap.Error(err))
+		}
+		for _, guildID := range guildIDs {
+			if err := b.deleteGuildData(guildID); err != nil {
+				zap.L().Error("failed to delete guild data", zap.Error(err), zap.String("guild_id", guildID))
+				continue
+			}
+			zap.L().Info("deleted data of guild the bot left", zap.String("guild_id", guildID))
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
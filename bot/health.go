@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/metrics"
+)
+
+// shardTracker keeps track of the gateway connection state of every shard,
+// so the readiness check can tell whether all of them are connected.
+type shardTracker struct {
+	mu        sync.RWMutex
+	count     int
+	connected map[int]bool
+}
+
+func newShardTracker() *shardTracker {
+	return &shardTracker{connected: make(map[int]bool)}
+}
+
+// setCount sets the number of shards the bot is expected to run, as
+// recommended by the gateway.
+func (t *shardTracker) setCount(count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count = count
+}
+
+// setConnected updates the connection state of the given shard.
+func (t *shardTracker) setConnected(shard int, connected bool) {
+	t.mu.Lock()
+	t.connected[shard] = connected
+	t.mu.Unlock()
+
+	value := 0.0
+	if connected {
+		value = 1
+	}
+	metrics.ShardConnected.WithLabelValues(strconv.Itoa(shard)).Set(value)
+}
+
+// status returns the connection state of every expected shard, and whether
+// all of them are connected. Before the gateway tells us how many shards to
+// run, the bot is never considered connected.
+func (t *shardTracker) status() ([]shardStatus, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	allConnected := t.count > 0
+	shards := make([]shardStatus, t.count)
+	for i := 0; i < t.count; i++ {
+		shards[i] = shardStatus{ID: i, Connected: t.connected[i]}
+		allConnected = allConnected && t.connected[i]
+	}
+	return shards, allConnected
+}
+
+type shardStatus struct {
+	ID        int  `json:"id"`
+	Connected bool `json:"connected"`
+}
+
+type checkStatus struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type dataStatus struct {
+	OK       bool       `json:"ok"`
+	LoadedAt *time.Time `json:"loadedAt,omitempty"`
+}
+
+type readinessReport struct {
+	Ready    bool          `json:"ready"`
+	Shards   []shardStatus `json:"shards"`
+	Database checkStatus   `json:"database"`
+	Data     dataStatus    `json:"data"`
+}
+
+// handleHealthz reports the process is alive and able to serve requests.
+func (b *Bot) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports whether the bot is ready to take commands, that is,
+// every shard is connected to the gateway, the database is reachable and the
+// data has been loaded. It fails with 503 otherwise.
+func (b *Bot) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	report := readinessReport{}
+
+	var shardsReady bool
+	report.Shards, shardsReady = b.shards.status()
+
+	report.Database.OK = true
+	if err := b.repository.Ping(); err != nil {
+		report.Database = checkStatus{Error: err.Error()}
+	}
+
+	if loadedAt := b.repository.DataLoadedAt(); !loadedAt.IsZero() {
+		report.Data = dataStatus{OK: true, LoadedAt: &loadedAt}
+	}
+
+	report.Ready = shardsReady && report.Database.OK && report.Data.OK
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		zap.L().Error("failed to write HTTP response", zap.Error(err))
+	}
+}
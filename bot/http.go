@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const httpShutdownTimeout = 5 * time.Second
+
+// startHTTPServer starts the HTTP listener in the background, exposing the
+// bot's metrics along with the health and readiness checks. The returned
+// server must be shut down by the caller.
+func (b *Bot) startHTTPServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", b.handleHealthz)
+	mux.HandleFunc("/readyz", b.handleReadyz)
+
+	server := &http.Server{
+		Addr:    b.config.HTTP.Address,
+		Handler: mux,
+	}
+
+	go func() {
+		zap.L().Info("starting HTTP server", zap.String("address", server.Addr))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			zap.L().Error("HTTP server stopped", zap.Error(err))
+		}
+	}()
+	return server
+}
+
+// stopHTTPServer gracefully shuts down the given HTTP server.
+func stopHTTPServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		zap.L().Error("failed to shut down HTTP server", zap.Error(err))
+	}
+}
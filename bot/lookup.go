@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+// suggestionsCount is how many "did you mean" suggestions to show when a
+// lookup fails.
+const suggestionsCount = 3
+
+// findPokemon looks up the given Pokémon. If it does not exist, the closest
+// match is used as long as it is an obvious typo, otherwise a public error
+// with the closest matches is returned.
+func (b *Bot) findPokemon(name string) (*repository.Pokemon, error) {
+	pkm, err := b.repository.Pokemon(name)
+	if err == nil {
+		return pkm, nil
+	}
+
+	matches := b.repository.PokemonSuggestions(name, suggestionsCount)
+	if match, ok := b.autoResolve(matches); ok {
+		return b.repository.Pokemon(match)
+	}
+	return nil, notFoundError("Pokémon", name, matches)
+}
+
+// findBall looks up the given ball, falling back to the closest match the
+// same way findPokemon does.
+func (b *Bot) findBall(name string) (*repository.PokeBall, error) {
+	ball, err := b.repository.Ball(name)
+	if err == nil {
+		return ball, nil
+	}
+
+	matches := b.repository.BallSuggestions(name, suggestionsCount)
+	if match, ok := b.autoResolve(matches); ok {
+		return b.repository.Ball(match)
+	}
+	return nil, notFoundError("Pokéball", name, matches)
+}
+
+// findPokemonType looks up the given type, falling back to the closest match
+// the same way findPokemon does.
+func (b *Bot) findPokemonType(name string) (*repository.PokemonType, error) {
+	t, err := b.repository.PokemonType(name)
+	if err == nil {
+		return t, nil
+	}
+
+	matches := b.repository.PokemonTypeSuggestions(name, suggestionsCount)
+	if match, ok := b.autoResolve(matches); ok {
+		return b.repository.PokemonType(match)
+	}
+	return nil, notFoundError("Type", name, matches)
+}
+
+// findNature looks up the given nature, returning its name and description.
+// It falls back to the closest match the same way findPokemon does.
+func (b *Bot) findNature(name string) (string, string, error) {
+	nature := strings.Title(strings.ToLower(name))
+	if info, ok := natures[nature]; ok {
+		return nature, info, nil
+	}
+
+	names := make([]string, 0, len(natures))
+	for n := range natures {
+		names = append(names, n)
+	}
+	matches := repository.ClosestMatches(name, names, suggestionsCount)
+	if match, ok := b.autoResolve(matches); ok {
+		return match, natures[match], nil
+	}
+	return "", "", notFoundError("Nature", name, matches)
+}
+
+// autoResolve returns the best match if it is similar enough to be considered
+// a typo. If more than one match shares the best score, it is not obvious
+// which one the user meant, so nothing is resolved.
+func (b *Bot) autoResolve(matches []repository.Match) (string, bool) {
+	threshold := b.config.Bot.FuzzyMatchThreshold
+	if threshold <= 0 || len(matches) == 0 || matches[0].Score < threshold {
+		return "", false
+	}
+	if len(matches) > 1 && matches[1].Score == matches[0].Score {
+		return "", false
+	}
+	return matches[0].Name, true
+}
+
+// notFoundError builds the public error for a failed lookup, listing the
+// closest matches if there are any.
+func notFoundError(kind, name string, matches []repository.Match) botError {
+	details := fmt.Sprintf("%s %s could not be found.", kind, name)
+	if len(matches) > 0 {
+		suggestions := make([]string, len(matches))
+		for i, m := range matches {
+			suggestions[i] = "`" + m.Name + "`"
+		}
+		details += "\nDid you mean " + strings.Join(suggestions, ", ") + "?"
+	}
+	return botError{
+		title:   kind + " not found",
+		details: details,
+	}
+}
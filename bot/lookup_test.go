@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/caquillo07/rotom-bot/conf"
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+func TestAutoResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		matches   []repository.Match
+		want      string
+		wantOK    bool
+	}{
+		{
+			name:      "best match over the threshold",
+			threshold: 0.8,
+			matches:   []repository.Match{{Name: "Charizard", Score: 0.9}, {Name: "Charmander", Score: 0.6}},
+			want:      "Charizard",
+			wantOK:    true,
+		},
+		{
+			name:      "best match on the threshold",
+			threshold: 0.8,
+			matches:   []repository.Match{{Name: "Eevee", Score: 0.8}},
+			want:      "Eevee",
+			wantOK:    true,
+		},
+		{
+			name:      "best match under the threshold",
+			threshold: 0.8,
+			matches:   []repository.Match{{Name: "Charizard", Score: 0.7}},
+		},
+		{
+			name:      "tied best matches",
+			threshold: 0.5,
+			matches:   []repository.Match{{Name: "Charmander", Score: 0.7}, {Name: "Charmeleon", Score: 0.7}},
+		},
+		{
+			name:      "disabled",
+			threshold: 0,
+			matches:   []repository.Match{{Name: "Pikachu", Score: 1}},
+		},
+		{
+			name:      "no matches",
+			threshold: 0.8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := conf.Config{}
+			config.Bot.FuzzyMatchThreshold = tt.threshold
+			b := NewBot(config)
+
+			got, ok := b.autoResolve(tt.matches)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("autoResolve() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
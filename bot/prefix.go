@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+// maxPrefixes is the maximum number of prefixes a guild can have.
+const maxPrefixes = 5
+
+// mainPrefix returns the prefix shown to users on help messages, which is the
+// first of the guild's prefixes, or the global prefix if there are none.
+func (b *Bot) mainPrefix(settings *repository.GuildSettings) string {
+	if len(settings.BotPrefixes) > 0 && settings.BotPrefixes[0] != "" {
+		return settings.BotPrefixes[0]
+	}
+	return b.config.Bot.Prefix
+}
+
+// trimCommandPrefix removes the prefix from the message content, and reports
+// whether the message had one. Mentioning the bot always works as a prefix,
+// so users who forgot the guild's prefix can still reach the bot.
+func (b *Bot) trimCommandPrefix(content, botUserID string, settings *repository.GuildSettings) (string, bool) {
+	for _, mention := range []string{"<@" + botUserID + ">", "<@!" + botUserID + ">"} {
+		if strings.HasPrefix(content, mention) {
+			return strings.TrimSpace(strings.TrimPrefix(content, mention)), true
+		}
+	}
+
+	prefixes := make([]string, 0, len(settings.BotPrefixes))
+	for _, p := range settings.BotPrefixes {
+		if p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	if len(prefixes) == 0 {
+		prefixes = append(prefixes, b.config.Bot.Prefix)
+	}
+
+	// check the longest prefixes first, so "!!" wins over "!" when a guild
+	// has both.
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	for _, p := range prefixes {
+		if strings.HasPrefix(content, p) {
+			return strings.TrimPrefix(content, p), true
+		}
+	}
+	return "", false
+}
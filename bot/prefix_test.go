@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/caquillo07/rotom-bot/conf"
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+func TestTrimCommandPrefix(t *testing.T) {
+	const botUserID = "1234"
+	tests := []struct {
+		name     string
+		content  string
+		prefixes []string
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:    "global prefix",
+			content: "!den 1",
+			want:    "den 1",
+			wantOK:  true,
+		},
+		{
+			name:     "empty prefixes use the global prefix",
+			content:  "!den 1",
+			prefixes: []string{""},
+			want:     "den 1",
+			wantOK:   true,
+		},
+		{
+			name:     "guild prefix",
+			content:  "$den 1",
+			prefixes: []string{"$"},
+			want:     "den 1",
+			wantOK:   true,
+		},
+		{
+			name:     "guild prefix replaces the global prefix",
+			content:  "!den 1",
+			prefixes: []string{"$"},
+		},
+		{
+			name:     "longest prefix wins",
+			content:  "!!den 1",
+			prefixes: []string{"!", "!!"},
+			want:     "den 1",
+			wantOK:   true,
+		},
+		{
+			name:     "multi character prefix",
+			content:  "rotom den 1",
+			prefixes: []string{"rotom "},
+			want:     "den 1",
+			wantOK:   true,
+		},
+		{
+			name:    "no prefix",
+			content: "den 1",
+		},
+		{
+			name:     "user mention",
+			content:  "<@1234> den 1",
+			prefixes: []string{"$"},
+			want:     "den 1",
+			wantOK:   true,
+		},
+		{
+			name:    "nickname mention",
+			content: "<@!1234>den 1",
+			want:    "den 1",
+			wantOK:  true,
+		},
+		{
+			name:    "mention of another user",
+			content: "<@5678> den 1",
+		},
+		{
+			name:    "mention without a command",
+			content: "<@1234>",
+			want:    "",
+			wantOK:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := conf.Config{}
+			config.Bot.Prefix = "!"
+			b := NewBot(config)
+			settings := &repository.GuildSettings{BotPrefixes: tt.prefixes}
+
+			got, ok := b.trimCommandPrefix(tt.content, botUserID, settings)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("trimCommandPrefix() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMainPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     string
+	}{
+		{"no prefixes", nil, "!"},
+		{"empty prefix", []string{""}, "!"},
+		{"first prefix", []string{"$", "%"}, "$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := conf.Config{}
+			config.Bot.Prefix = "!"
+			b := NewBot(config)
+
+			got := b.mainPrefix(&repository.GuildSettings{BotPrefixes: tt.prefixes})
+			if got != tt.want {
+				t.Errorf("mainPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
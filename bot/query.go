@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	// QueryFormatText renders the query responses as plain text
+	QueryFormatText = "text"
+
+	// QueryFormatJSON renders the query responses as the JSON embeds that
+	// would be sent to discord
+	QueryFormatJSON = "json"
+)
+
+// ErrQueryFailed is returned by Query when the command failed, after its
+// error was already written as the response.
+var ErrQueryFailed = errors.New("the command failed")
+
+// queryCommands are the commands that only need the JSON data, the rest
+// need a guild, a discord session or the database.
+var queryCommands = []string{
+	"help", "den", "ball", "catch", "credits", "nature", "type", "pokedex", "sprite", "invite", "version",
+}
+
+// Query runs a single bot command without connecting to discord, and writes
+// the response to the given writer in the given format. Only the data backed
+// commands listed in queryCommands are available, anything that requires a
+// guild or the database is rejected.
+func (b *Bot) Query(w io.Writer, format string, args []string) (err error) {
+	if format != QueryFormatText && format != QueryFormatJSON {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if len(args) == 0 {
+		return errors.New("a command is required")
+	}
+
+	// Queries never touch the database, so the repository only needs the
+	// JSON data.
+	if b.repository == nil {
+		repo, err := repository.NewRepository(nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to create repository")
+		}
+		b.repository = repo
+	}
+	if len(b.commands) == 0 {
+		b.initCommands()
+	}
+
+	args[0] = strings.ToLower(args[0])
+	botCmd, ok := b.commands[args[0]]
+	if !ok {
+		return fmt.Errorf("command %q does not exist", args[0])
+	}
+	if botCmd.alias != "" {
+		botCmd, ok = b.commands[botCmd.alias]
+		if !ok {
+			return fmt.Errorf("command %q does not exist", args[0])
+		}
+	}
+	if !contains(queryCommands, botCmd.name) {
+		return fmt.Errorf("command %q is not available in query mode", args[0])
+	}
+
+	env := &commandEnvironment{
+		args:          args[1:],
+		command:       args[0],
+		commandPrefix: b.config.Bot.Prefix,
+		reqID:         atomic.AddUint64(&b.requestsServed, 1),
+		user:          &discordgo.User{Username: "query"},
+		guildSettings: b.defaultGuildSettings(),
+		ctx:           context.Background(),
+		responder:     &writerResponder{w: w, format: format},
+		bot:           b,
+	}
+
+	// handlers are written for discord, so a panic here is turned into an
+	// error instead of crashing with a stack trace.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("command %q panicked: %v", args[0], r)
+		}
+	}()
+
+	if err := botCmd.execute(env); err != nil {
+		if replyErr := env.ReplyError(err); replyErr != nil {
+			return replyErr
+		}
+		return ErrQueryFailed
+	}
+	return nil
+}
+
+// writerResponder renders the command responses into a writer, this is used
+// to run commands from the terminal.
+type writerResponder struct {
+	w      io.Writer
+	format string
+}
+
+func (r *writerResponder) Reply(_ context.Context, embed *discordgo.MessageEmbed) error {
+	if r.format == QueryFormatJSON {
+		return r.writeJSON(embed)
+	}
+	_, err := io.WriteString(r.w, embedText(embed))
+	return err
+}
+
+func (r *writerResponder) ReplyFile(_ context.Context, name string, f io.Reader, embed *discordgo.MessageEmbed) error {
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if r.format == QueryFormatJSON {
+		return r.writeJSON(struct {
+			Embed    *discordgo.MessageEmbed `json:"embed,omitempty"`
+			FileName string                  `json:"file_name"`
+			File     string                  `json:"file"`
+		}{embed, name, string(content)})
+	}
+
+	var text string
+	if embed != nil {
+		text = embedText(embed)
+	}
+	text += fmt.Sprintf("--- %s ---\n%s\n", name, content)
+	_, err = io.WriteString(r.w, text)
+	return err
+}
+
+func (r *writerResponder) ReplyText(_ context.Context, content string) error {
+	if r.format == QueryFormatJSON {
+		return r.writeJSON(struct {
+			Content string `json:"content"`
+		}{content})
+	}
+	_, err := io.WriteString(r.w, content+"\n")
+	return err
+}
+
+func (r *writerResponder) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// embedText renders the given embed as plain text.
+func embedText(embed *discordgo.MessageEmbed) string {
+	var sb strings.Builder
+	if embed.Title != "" {
+		sb.WriteString(embed.Title + "\n")
+		sb.WriteString(strings.Repeat("=", len([]rune(embed.Title))) + "\n")
+	}
+	if embed.URL != "" {
+		sb.WriteString(embed.URL + "\n")
+	}
+	if d := strings.TrimSpace(embed.Description); d != "" {
+		sb.WriteString("\n" + d + "\n")
+	}
+	for _, field := range embed.Fields {
+		sb.WriteString("\n" + field.Name + "\n")
+		for _, line := range strings.Split(field.Value, "\n") {
+			sb.WriteString("  " + strings.TrimSpace(line) + "\n")
+		}
+	}
+	if embed.Thumbnail != nil && embed.Thumbnail.URL != "" {
+		sb.WriteString("\nThumbnail: " + embed.Thumbnail.URL + "\n")
+	}
+	if embed.Image != nil && embed.Image.URL != "" {
+		sb.WriteString("\nImage: " + embed.Image.URL + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,472 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	// maxRaidChallengers is how many players can join a host on a Max Raid
+	maxRaidChallengers = 3
+
+	raidJoinEmoji  = "✅"
+	raidCloseEmoji = "❌"
+
+	raidLobbiesExpiryInterval = 30 * time.Second
+)
+
+// trackedMessages keeps track of the messages the bot listens to reactions
+// on, like open raid lobbies, so reactions on any other message don't have to
+// go to the database. The records themselves live in the database, this is
+// rebuilt from it on startup.
+type trackedMessages struct {
+	// mu only guards open. Each message has a lock of its own, held for the
+	// whole time its record is being updated, so concurrent reactions on it
+	// don't overwrite each other's changes without holding up the reactions
+	// on every other message.
+	mu   sync.Mutex
+	open map[string]*trackedMessage
+}
+
+type trackedMessage struct {
+	mu      sync.Mutex
+	guildID string
+}
+
+func newTrackedMessages() *trackedMessages {
+	return &trackedMessages{open: make(map[string]*trackedMessage)}
+}
+
+// track starts tracking the reactions on the guild's message.
+func (t *trackedMessages) track(guildID, messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.open[messageID]; !ok {
+		t.open[messageID] = &trackedMessage{guildID: guildID}
+	}
+}
+
+// untrack stops tracking the reactions on the message.
+func (t *trackedMessages) untrack(messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.open, messageID)
+}
+
+// lock takes the message's lock, and reports whether the message is tracked.
+// The caller must call unlock once done if it is.
+func (t *trackedMessages) lock(messageID string) (unlock func(), ok bool) {
+	t.mu.Lock()
+	message, ok := t.open[messageID]
+	t.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	// the message may have stopped being tracked while waiting for its lock
+	message.mu.Lock()
+	t.mu.Lock()
+	current := t.open[messageID]
+	t.mu.Unlock()
+	if current != message {
+		message.mu.Unlock()
+		return nil, false
+	}
+	return message.mu.Unlock, true
+}
+
+// untrackGuild stops tracking all the guild's messages, and waits for the
+// updates already running on them to finish.
+func (t *trackedMessages) untrackGuild(guildID string) {
+	t.mu.Lock()
+	messages := make([]*trackedMessage, 0)
+	for messageID, message := range t.open {
+		if message.guildID == guildID {
+			messages = append(messages, message)
+			delete(t.open, messageID)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, message := range messages {
+		message.mu.Lock()
+		message.mu.Unlock()
+	}
+}
+
+// loadRaidLobbies tracks the lobbies that were open when the bot stopped.
+func (b *Bot) loadRaidLobbies() error {
+	lobbies, err := b.repository.OpenRaidLobbies()
+	if err != nil {
+		return err
+	}
+
+	for _, lobby := range lobbies {
+		b.lobbies.track(lobby.GuildID, lobby.MessageID)
+	}
+	return nil
+}
+
+// withRaidLobby runs f with the open lobby posted on the given message, if
+// there is one. f must save any changes it makes to the lobby.
+func (b *Bot) withRaidLobby(messageID string, f func(lobby *repository.RaidLobby) error) error {
+	unlock, ok := b.lobbies.lock(messageID)
+	if !ok {
+		return nil
+	}
+	defer unlock()
+
+	lobby, err := b.repository.GetRaidLobby(messageID)
+	if err == repository.ErrRecordNotFound {
+		b.lobbies.untrack(messageID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lobby.ClosedAt != nil {
+		b.lobbies.untrack(messageID)
+		return nil
+	}
+	return f(lobby)
+}
+
+// raidReactionAdd adds challengers to the lobby when they react to join it,
+// and closes it when the host reacts to close it.
+func (b *Bot) raidReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r.GuildID == "" || r.UserID == s.State.User.ID {
+		return
+	}
+	if r.Emoji.Name != raidJoinEmoji && r.Emoji.Name != raidCloseEmoji {
+		return
+	}
+
+	err := b.withRaidLobby(r.MessageID, func(lobby *repository.RaidLobby) error {
+		if r.Emoji.Name == raidCloseEmoji {
+			if r.UserID != lobby.HostID {
+				return nil
+			}
+			return b.closeRaidLobby(s, lobby, "closed by the host")
+		}
+
+		if r.UserID == lobby.HostID || contains(lobby.Challengers, r.UserID) || contains(lobby.Queue, r.UserID) {
+			return nil
+		}
+		if b.raidChallengerIgnored(lobby, r.UserID, r.Member) {
+			return nil
+		}
+
+		accepted := len(lobby.Challengers) < maxRaidChallengers
+		if accepted {
+			lobby.Challengers = append(lobby.Challengers, r.UserID)
+		} else {
+			lobby.Queue = append(lobby.Queue, r.UserID)
+		}
+		if err := b.repository.UpdateRaidLobby(lobby); err != nil {
+			return err
+		}
+
+		b.updateRaidLobbyMessage(s, lobby)
+		if accepted {
+			b.sendRaidCode(s, lobby, r.UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("failed to join raid lobby", zap.Error(err), zap.String("message_id", r.MessageID))
+	}
+}
+
+// raidReactionRemove takes challengers out of the lobby when they remove
+// their reaction, giving their spot to the first user in the queue.
+func (b *Bot) raidReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	if r.GuildID == "" || r.Emoji.Name != raidJoinEmoji {
+		return
+	}
+
+	err := b.withRaidLobby(r.MessageID, func(lobby *repository.RaidLobby) error {
+		if !contains(lobby.Challengers, r.UserID) && !contains(lobby.Queue, r.UserID) {
+			return nil
+		}
+
+		lobby.Challengers = without(lobby.Challengers, r.UserID)
+		lobby.Queue = without(lobby.Queue, r.UserID)
+		promoted := ""
+		if len(lobby.Challengers) < maxRaidChallengers && len(lobby.Queue) > 0 {
+			promoted = lobby.Queue[0]
+			lobby.Challengers = append(lobby.Challengers, promoted)
+			lobby.Queue = lobby.Queue[1:]
+		}
+		if err := b.repository.UpdateRaidLobby(lobby); err != nil {
+			return err
+		}
+
+		b.updateRaidLobbyMessage(s, lobby)
+		if promoted != "" {
+			b.sendRaidCode(s, lobby, promoted)
+		}
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("failed to leave raid lobby", zap.Error(err), zap.String("message_id", r.MessageID))
+	}
+}
+
+// raidChallengerIgnored checks whether the guild ignores the user, in which
+// case they can't join lobbies either.
+func (b *Bot) raidChallengerIgnored(lobby *repository.RaidLobby, userID string, member *discordgo.Member) bool {
+	settings, err := b.repository.GetGuildSettings(lobby.GuildID)
+	if err != nil {
+		zap.L().Error("failed to get guild settings", zap.Error(err), zap.String("guild_id", lobby.GuildID))
+		return false
+	}
+
+	var roles []string
+	if member != nil {
+		roles = member.Roles
+	}
+	return isIgnored(settings, b.commands["host"], lobby.ChannelID, userID, roles)
+}
+
+// closeRaidLobby closes the lobby, and posts a summary of it on its channel.
+func (b *Bot) closeRaidLobby(s *discordgo.Session, lobby *repository.RaidLobby, reason string) error {
+	now := time.Now()
+	lobby.ClosedAt = &now
+	if err := b.repository.UpdateRaidLobby(lobby); err != nil {
+		return err
+	}
+	b.lobbies.untrack(lobby.MessageID)
+
+	b.updateRaidLobbyMessage(s, lobby)
+
+	embed := b.newEmbed()
+	embed.Title = "Raid Closed"
+	embed.Description = fmt.Sprintf(
+		"<@%s>'s raid for %s was %s after %s.",
+		lobby.HostID,
+		raidLobbySubject(lobby),
+		reason,
+		now.Sub(lobby.CreatedAt).Round(time.Minute),
+	)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:  "Challengers",
+			Value: mentionListOrNone(lobby.Challengers),
+		},
+	}
+	if _, err := s.ChannelMessageSendEmbed(lobby.ChannelID, embed); err != nil {
+		zap.L().Warn("failed to send raid summary", zap.Error(err), zap.String("channel_id", lobby.ChannelID))
+	}
+	return nil
+}
+
+// closeExpiredRaidLobbies closes the lobbies once their time is up, including
+// the ones that expired while the bot was down.
+func (b *Bot) closeExpiredRaidLobbies(done <-chan struct{}) {
+	ticker := time.NewTicker(raidLobbiesExpiryInterval)
+	defer ticker.Stop()
+	for {
+		lobbies, err := b.repository.RaidLobbiesExpiredBy(time.Now())
+		if err != nil {
+			zap.L().Error("failed to find expired raid lobbies", zap.Error(err))
+		}
+		for _, expired := range lobbies {
+			s := b.sessionForGuild(expired.GuildID)
+			err := b.withRaidLobby(expired.MessageID, func(lobby *repository.RaidLobby) error {
+				return b.closeRaidLobby(s, lobby, "closed automatically")
+			})
+			if err != nil {
+				zap.L().Error("failed to close raid lobby", zap.Error(err), zap.String("message_id", expired.MessageID))
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sessionForGuild returns the session of the shard the guild is on.
+func (b *Bot) sessionForGuild(guildID string) *discordgo.Session {
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return b.sessions[0]
+	}
+	return b.sessions[(id>>22)%uint64(len(b.sessions))]
+}
+
+// updateRaidLobbyMessage updates the lobby message with its current state.
+func (b *Bot) updateRaidLobbyMessage(s *discordgo.Session, lobby *repository.RaidLobby) {
+	if _, err := s.ChannelMessageEditEmbed(lobby.ChannelID, lobby.MessageID, b.raidLobbyEmbed(lobby)); err != nil {
+		zap.L().Warn("failed to update raid lobby", zap.Error(err), zap.String("message_id", lobby.MessageID))
+	}
+}
+
+// sendRaidCode lets the challenger know they are in, along with the link code
+// if the host shared one.
+func (b *Bot) sendRaidCode(s *discordgo.Session, lobby *repository.RaidLobby, userID string) {
+	embed := b.newEmbed()
+	embed.Title = "You're In!"
+	embed.Description = fmt.Sprintf(
+		"You joined <@%s>'s raid for %s on <#%s>.",
+		lobby.HostID,
+		raidLobbySubject(lobby),
+		lobby.ChannelID,
+	)
+
+	code := "The host did not set a link code, ask them for it on the raid's channel."
+	if lobby.Code != "" {
+		code = "**" + formatLinkCode(lobby.Code) + "**"
+	}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Link Code", Value: code},
+	}
+
+	channel, err := s.UserChannelCreate(userID)
+	if err == nil {
+		_, err = s.ChannelMessageSendEmbed(channel.ID, embed)
+	}
+	if err != nil {
+		zap.L().Warn("failed to send raid link code", zap.Error(err), zap.String("user_id", userID))
+	}
+}
+
+func (b *Bot) raidLobbyEmbed(lobby *repository.RaidLobby) *discordgo.MessageEmbed {
+	embed := b.newEmbed()
+	embed.Title = "Max Raid: " + raidLobbySubject(lobby)
+
+	if lobby.ClosedAt != nil {
+		embed.Description = fmt.Sprintf("Hosted by <@%s>. This raid is closed.", lobby.HostID)
+	} else {
+		embed.Description = fmt.Sprintf(
+			"Hosted by <@%s>. React with %s to join, the first %d challengers get the link code by DM. "+
+				"The host can react with %s to close the raid.",
+			lobby.HostID, raidJoinEmoji, maxRaidChallengers, raidCloseEmoji,
+		)
+	}
+
+	if lobby.Pokemon != "" {
+		if pkm, err := b.repository.Pokemon(lobby.Pokemon); err == nil {
+			form := ""
+			if lobby.Gigantamax {
+				form = repository.GetSpriteForm("gigantamax")
+			}
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+				URL:    pkm.SpriteImage(lobby.Shiny, form),
+				Width:  150,
+				Height: 150,
+			}
+		}
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   fmt.Sprintf("Challengers (%d/%d)", len(lobby.Challengers), maxRaidChallengers),
+			Value:  mentionListOrNone(lobby.Challengers),
+			Inline: true,
+		},
+	}
+	if len(lobby.Queue) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Queue",
+			Value:  mentionList(lobby.Queue, "<@%s>"),
+			Inline: true,
+		})
+	}
+
+	if lobby.Den != "" {
+		if den, err := b.repository.Den(lobby.Den); err == nil {
+			embed.Fields = append(embed.Fields,
+				&discordgo.MessageEmbedField{Name: "Sword", Value: denPokemonNames(den.Sword), Inline: false},
+				&discordgo.MessageEmbedField{Name: "Shield", Value: denPokemonNames(den.Shield), Inline: false},
+			)
+		}
+	}
+
+	timer := &discordgo.MessageEmbedField{
+		Name:   "Closes",
+		Value:  fmt.Sprintf("<t:%d:R>", lobby.ExpiresAt.Unix()),
+		Inline: true,
+	}
+	if lobby.ClosedAt != nil {
+		timer.Name = "Closed"
+		timer.Value = fmt.Sprintf("<t:%d:R>", lobby.ClosedAt.Unix())
+	}
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{
+			Name:   "Opened",
+			Value:  fmt.Sprintf("<t:%d:R>", lobby.CreatedAt.Unix()),
+			Inline: true,
+		},
+		timer,
+	)
+	return embed
+}
+
+// raidLobbySubject describes what the lobby is hosting, i.e. "Shiny
+// Gigantamax Toxtricity" or "Den 22".
+func raidLobbySubject(lobby *repository.RaidLobby) string {
+	if lobby.Den != "" {
+		return "Den " + lobby.Den
+	}
+
+	subject := lobby.Pokemon
+	if lobby.Gigantamax {
+		subject = "Gigantamax " + subject
+	}
+	if lobby.Shiny {
+		subject = "Shiny " + subject
+	}
+	return subject
+}
+
+func denPokemonNames(pokemon []*repository.DenPokemon) string {
+	names := make([]string, len(pokemon))
+	for i, p := range pokemon {
+		names[i] = p.Name
+		if p.Gigantamax {
+			names[i] += " (G-Max)"
+		}
+	}
+	if len(names) == 0 {
+		return "N/A"
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatLinkCode splits 8 digit link codes in two, the way the game shows
+// them.
+func formatLinkCode(code string) string {
+	if len(code) == 8 {
+		return code[:4] + " " + code[4:]
+	}
+	return code
+}
+
+func mentionListOrNone(ids []string) string {
+	if len(ids) == 0 {
+		return "None yet"
+	}
+	return mentionList(ids, "<@%s>")
+}
+
+// without returns the list without the given string.
+func without(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, l := range list {
+		if l != s {
+			result = append(result, l)
+		}
+	}
+	return result
+}
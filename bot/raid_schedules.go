@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+const (
+	raidSchedulerInterval = 30 * time.Second
+
+	// raidReminderMentions is how many users are pinged on each message and
+	// listed on the announcement, to stay under Discord's
+	// message and embed field length limits.
+	raidReminderMentions = 40
+)
+
+// loadRaidSchedules tracks the raids that were upcoming when the bot stopped.
+func (b *Bot) loadRaidSchedules() error {
+	schedules, err := b.repository.UpcomingRaidSchedules("")
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		b.schedules.track(schedule.GuildID, schedule.MessageID)
+	}
+	return nil
+}
+
+// withRaidSchedule runs f with the upcoming raid announced on the given
+// message, if there is one. f must save any changes it makes to the raid.
+func (b *Bot) withRaidSchedule(messageID string, f func(schedule *repository.RaidSchedule) error) error {
+	unlock, ok := b.schedules.lock(messageID)
+	if !ok {
+		return nil
+	}
+	defer unlock()
+
+	schedule, err := b.repository.GetRaidScheduleByMessage(messageID)
+	if err == repository.ErrRecordNotFound {
+		b.schedules.untrack(messageID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if schedule.CancelledAt != nil || schedule.StartsAt.Before(time.Now()) {
+		b.schedules.untrack(messageID)
+		return nil
+	}
+	return f(schedule)
+}
+
+// raidScheduleReactionAdd RSVPs users to the raid when they react to its
+// announcement.
+func (b *Bot) raidScheduleReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r.GuildID == "" || r.UserID == s.State.User.ID || r.Emoji.Name != raidJoinEmoji {
+		return
+	}
+
+	err := b.withRaidSchedule(r.MessageID, func(schedule *repository.RaidSchedule) error {
+		if r.UserID == schedule.HostID || contains(schedule.RSVPs, r.UserID) {
+			return nil
+		}
+
+		settings, err := b.repository.GetGuildSettings(schedule.GuildID)
+		if err != nil {
+			return err
+		}
+		var roles []string
+		if r.Member != nil {
+			roles = r.Member.Roles
+		}
+		if isIgnored(settings, b.commands["raid"], schedule.ChannelID, r.UserID, roles) {
+			return nil
+		}
+
+		schedule.RSVPs = append(schedule.RSVPs, r.UserID)
+		if err := b.repository.UpdateRaidSchedule(schedule); err != nil {
+			return err
+		}
+		b.updateRaidScheduleMessage(s, schedule, settings)
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("failed to RSVP to raid", zap.Error(err), zap.String("message_id", r.MessageID))
+	}
+}
+
+// raidScheduleReactionRemove takes back the RSVP of users that remove their
+// reaction.
+func (b *Bot) raidScheduleReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	if r.GuildID == "" || r.Emoji.Name != raidJoinEmoji {
+		return
+	}
+
+	err := b.withRaidSchedule(r.MessageID, func(schedule *repository.RaidSchedule) error {
+		if !contains(schedule.RSVPs, r.UserID) {
+			return nil
+		}
+
+		schedule.RSVPs = without(schedule.RSVPs, r.UserID)
+		if err := b.repository.UpdateRaidSchedule(schedule); err != nil {
+			return err
+		}
+
+		settings, err := b.repository.GetGuildSettings(schedule.GuildID)
+		if err != nil {
+			return err
+		}
+		b.updateRaidScheduleMessage(s, schedule, settings)
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("failed to remove raid RSVP", zap.Error(err), zap.String("message_id", r.MessageID))
+	}
+}
+
+// runRaidScheduler pings the host and the users that RSVP'd to the scheduled
+// raids before they start. Reminders are kept in the database, so the ones
+// that came due while the bot was down are sent once it is back, as long as
+// the raid has not started yet.
+func (b *Bot) runRaidScheduler(done <-chan struct{}) {
+	ticker := time.NewTicker(raidSchedulerInterval)
+	defer ticker.Stop()
+	for {
+		schedules, err := b.repository.RaidSchedulesDueForReminder(time.Now())
+		if err != nil {
+			zap.L().Error("failed to find raids due for a reminder", zap.Error(err))
+		}
+		for _, due := range schedules {
+			err := b.withRaidSchedule(due.MessageID, func(schedule *repository.RaidSchedule) error {
+				return b.sendRaidReminder(b.sessionForGuild(schedule.GuildID), schedule)
+			})
+			if err != nil {
+				zap.L().Error("failed to send raid reminder", zap.Error(err), zap.String("message_id", due.MessageID))
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendRaidReminder pings the host and the users that RSVP'd, and marks the
+// reminder as sent. The reminder is only sent once, even if Discord fails to
+// deliver it.
+func (b *Bot) sendRaidReminder(s *discordgo.Session, schedule *repository.RaidSchedule) error {
+	now := time.Now()
+	schedule.RemindedAt = &now
+	if err := b.repository.UpdateRaidSchedule(schedule); err != nil {
+		return err
+	}
+
+	users := append([]string{schedule.HostID}, schedule.RSVPs...)
+	content := fmt.Sprintf("**%s** starts <t:%d:R>!", schedule.Title, schedule.StartsAt.Unix())
+	b.pingUsers(s, schedule.ChannelID, schedule.MessageID, content, users)
+	return nil
+}
+
+// pingUsers sends the content mentioning the users, replying to the given
+// message. Users are split over as many messages as needed.
+func (b *Bot) pingUsers(s *discordgo.Session, channelID, messageID, content string, userIDs []string) {
+	for start := 0; start < len(userIDs); start += raidReminderMentions {
+		end := start + raidReminderMentions
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+
+		_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content:         content + " " + mentionList(userIDs[start:end], "<@%s>"),
+			AllowedMentions: &discordgo.MessageAllowedMentions{Users: userIDs[start:end]},
+			Reference:       &discordgo.MessageReference{MessageID: messageID, ChannelID: channelID},
+		})
+		if err != nil {
+			zap.L().Warn("failed to ping users", zap.Error(err), zap.String("channel_id", channelID))
+			return
+		}
+	}
+}
+
+// updateRaidScheduleMessage updates the announcement with the raid's current
+// state.
+func (b *Bot) updateRaidScheduleMessage(
+	s *discordgo.Session,
+	schedule *repository.RaidSchedule,
+	settings *repository.GuildSettings,
+) {
+	_, err := s.ChannelMessageEditEmbed(schedule.ChannelID, schedule.MessageID, b.raidScheduleEmbed(schedule, settings))
+	if err != nil {
+		zap.L().Warn("failed to update raid announcement", zap.Error(err), zap.String("message_id", schedule.MessageID))
+	}
+}
+
+func (b *Bot) raidScheduleEmbed(schedule *repository.RaidSchedule, settings *repository.GuildSettings) *discordgo.MessageEmbed {
+	embed := b.newEmbed()
+	embed.Title = "Scheduled Raid: " + schedule.Title
+
+	if schedule.CancelledAt != nil {
+		embed.Description = fmt.Sprintf("Hosted by <@%s>. This raid was cancelled.", schedule.HostID)
+	} else {
+		embed.Description = fmt.Sprintf(
+			"Hosted by <@%s>. React with %s to RSVP, and get pinged %d minutes before it starts.",
+			schedule.HostID, raidJoinEmoji, schedule.RemindMinutes,
+		)
+	}
+
+	going := mentionListOrNone(schedule.RSVPs)
+	if len(schedule.RSVPs) > raidReminderMentions {
+		going = fmt.Sprintf("%d trainers", len(schedule.RSVPs))
+	}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Starts",
+			Value:  fmt.Sprintf("%s\n<t:%d:F> (<t:%d:R>)", formatGuildTime(schedule.StartsAt, settings), schedule.StartsAt.Unix(), schedule.StartsAt.Unix()),
+			Inline: false,
+		},
+		{
+			Name:   fmt.Sprintf("Going (%d)", len(schedule.RSVPs)),
+			Value:  going,
+			Inline: false,
+		},
+	}
+	if schedule.ID != 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "ID",
+			Value:  fmt.Sprintf("`#%d`", schedule.ID),
+			Inline: true,
+		})
+	}
+	return embed
+}
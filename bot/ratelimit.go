@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+)
+
+const (
+	// slowDownMessageLifetime is how long the slow down message stays on the
+	// channel before it is deleted, so it doesn't add to the spam.
+	slowDownMessageLifetime = 5 * time.Second
+
+	// idleBucketExpiration is how long a guild's bucket is kept around after
+	// its last command.
+	idleBucketExpiration = 10 * time.Minute
+)
+
+// rateLimiter enforces the per user command cooldowns, and the per guild
+// token buckets. A single instance is shared by all the shards.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	// cooldowns holds when each user can run each command again, keyed by
+	// command name and user ID.
+	cooldowns *cache.Cache
+
+	// warnings holds the users that were already told to slow down, so
+	// they are only told once per cooldown.
+	warnings *cache.Cache
+
+	// buckets holds the *tokenBucket of each guild
+	buckets *cache.Cache
+
+	// rate is how many tokens per second a guild's bucket gets back, and
+	// burst is the most tokens a bucket can hold. A zero burst disables the
+	// guild limits.
+	rate  float64
+	burst int
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		cooldowns: cache.New(time.Minute, 5*time.Minute),
+		warnings:  cache.New(time.Minute, 5*time.Minute),
+		buckets:   cache.New(idleBucketExpiration, 2*idleBucketExpiration),
+		rate:      rate,
+		burst:     burst,
+	}
+}
+
+// allow checks whether the user can run the command on the guild right now.
+// If not, it returns how long they need to wait. Guild IDs are empty for
+// direct messages, which are limited per user instead.
+func (l *rateLimiter) allow(cmd *command, guildID, userID string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cooldownKey := cmd.name + ":" + userID
+	if cmd.cooldown > 0 {
+		if until, found := l.cooldowns.Get(cooldownKey); found {
+			if wait := until.(time.Time).Sub(now); wait > 0 {
+				return wait, false
+			}
+		}
+	}
+
+	if l.burst > 0 {
+		bucketKey := guildID
+		if bucketKey == "" {
+			bucketKey = "dm:" + userID
+		}
+
+		bucket := &tokenBucket{tokens: float64(l.burst), last: now}
+		if cached, found := l.buckets.Get(bucketKey); found {
+			bucket = cached.(*tokenBucket)
+		}
+
+		elapsed := now.Sub(bucket.last).Seconds()
+		bucket.tokens = math.Min(float64(l.burst), bucket.tokens+elapsed*l.rate)
+		bucket.last = now
+		l.buckets.Set(bucketKey, bucket, cache.DefaultExpiration)
+
+		if bucket.tokens < 1 {
+			if l.rate <= 0 {
+				return idleBucketExpiration, false
+			}
+			wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+			return wait, false
+		}
+		bucket.tokens--
+	}
+
+	if cmd.cooldown > 0 {
+		l.cooldowns.Set(cooldownKey, now.Add(cmd.cooldown), cmd.cooldown)
+	}
+	return 0, true
+}
+
+// shouldWarn reports whether the user should be told to slow down, which only
+// happens once for as long as they have to wait.
+func (l *rateLimiter) shouldWarn(cmd *command, userID string, wait time.Duration) bool {
+	return l.warnings.Add(cmd.name+":"+userID, true, wait) == nil
+}
+
+func (b *Bot) slowDownEmbed(wait time.Duration) *discordgo.MessageEmbed {
+	embed := b.newEmbed()
+	embed.Title = "Slow Down"
+	embed.Description = fmt.Sprintf(
+		"You are sending commands too fast, try again in %s.",
+		wait.Round(time.Second/10),
+	)
+	embed.Color = b.config.Bot.WarningEmbedColor
+	return embed
+}
+
+// sendTemporaryEmbed sends the embed to the channel, and deletes it once its
+// lifetime is over. Prefix commands have no ephemeral messages, so this is
+// the closest we can get.
+func sendTemporaryEmbed(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed, lifetime time.Duration) {
+	msg, err := s.ChannelMessageSendEmbed(channelID, embed)
+	if err != nil {
+		zap.L().Error("failed to send message", zap.Error(err), zap.String("channel_id", channelID))
+		return
+	}
+
+	time.AfterFunc(lifetime, func() {
+		if err := s.ChannelMessageDelete(channelID, msg.ID); err != nil {
+			zap.L().Warn("failed to delete message", zap.Error(err), zap.String("channel_id", channelID))
+		}
+	})
+}
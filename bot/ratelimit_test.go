@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+func TestRateLimiterCooldowns(t *testing.T) {
+	den := &command{name: "den", cooldown: time.Minute}
+	catch := &command{name: "catch", cooldown: time.Minute}
+	help := &command{name: "help"}
+
+	type call struct {
+		cmd      *command
+		userID   string
+		wantOK   bool
+		wantWait bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "second run waits for the cooldown",
+			calls: []call{
+				{cmd: den, userID: "1", wantOK: true},
+				{cmd: den, userID: "1", wantWait: true},
+			},
+		},
+		{
+			name: "cooldowns are per user",
+			calls: []call{
+				{cmd: den, userID: "1", wantOK: true},
+				{cmd: den, userID: "2", wantOK: true},
+			},
+		},
+		{
+			name: "cooldowns are per command",
+			calls: []call{
+				{cmd: den, userID: "1", wantOK: true},
+				{cmd: catch, userID: "1", wantOK: true},
+			},
+		},
+		{
+			name: "no cooldown",
+			calls: []call{
+				{cmd: help, userID: "1", wantOK: true},
+				{cmd: help, userID: "1", wantOK: true},
+				{cmd: help, userID: "1", wantOK: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the guild limits are disabled, so only the cooldowns apply
+			l := newRateLimiter(0, 0)
+			for i, c := range tt.calls {
+				wait, ok := l.allow(c.cmd, "guild", c.userID)
+				if ok != c.wantOK {
+					t.Fatalf("call %d: allow() ok = %v, want %v", i, ok, c.wantOK)
+				}
+				if c.wantWait && (wait <= 0 || wait > c.cmd.cooldown) {
+					t.Errorf("call %d: allow() wait = %s, want between 0 and %s", i, wait, c.cmd.cooldown)
+				}
+				if !c.wantWait && wait != 0 {
+					t.Errorf("call %d: allow() wait = %s, want 0", i, wait)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	help := &command{name: "help"}
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		tokens  float64
+		elapsed time.Duration
+		want    bool
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{
+			name:   "tokens left",
+			rate:   1,
+			burst:  5,
+			tokens: 1,
+			want:   true,
+		},
+		{
+			name:    "empty bucket waits for a token",
+			rate:    2,
+			burst:   5,
+			tokens:  0,
+			minWait: 400 * time.Millisecond,
+			maxWait: 500 * time.Millisecond,
+		},
+		{
+			name:    "partial token waits for the rest",
+			rate:    1,
+			burst:   5,
+			tokens:  0.75,
+			minWait: 200 * time.Millisecond,
+			maxWait: 250 * time.Millisecond,
+		},
+		{
+			name:    "refilled while idle",
+			rate:    1,
+			burst:   5,
+			tokens:  0,
+			elapsed: 2 * time.Second,
+			want:    true,
+		},
+		{
+			name:    "no refill rate",
+			rate:    0,
+			burst:   5,
+			tokens:  0,
+			elapsed: time.Hour,
+			minWait: idleBucketExpiration,
+			maxWait: idleBucketExpiration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(tt.rate, tt.burst)
+			l.buckets.Set("guild", &tokenBucket{
+				tokens: tt.tokens,
+				last:   time.Now().Add(-tt.elapsed),
+			}, cache.DefaultExpiration)
+
+			wait, ok := l.allow(help, "guild", "1")
+			if ok != tt.want {
+				t.Fatalf("allow() ok = %v, want %v", ok, tt.want)
+			}
+			if wait < tt.minWait || wait > tt.maxWait {
+				t.Errorf("allow() wait = %s, want between %s and %s", wait, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	help := &command{name: "help"}
+	l := newRateLimiter(0.001, 3)
+
+	for i := 0; i < 3; i++ {
+		if _, ok := l.allow(help, "guild", "1"); !ok {
+			t.Fatalf("call %d: allow() = false, want true", i)
+		}
+	}
+	if _, ok := l.allow(help, "guild", "2"); ok {
+		t.Errorf("allow() after the burst = true, want false")
+	}
+
+	// other guilds and direct messages have their own buckets
+	if _, ok := l.allow(help, "other", "1"); !ok {
+		t.Errorf("allow() on another guild = false, want true")
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := l.allow(help, "", "1"); !ok {
+			t.Fatalf("direct message %d: allow() = false, want true", i)
+		}
+	}
+	if _, ok := l.allow(help, "", "2"); !ok {
+		t.Errorf("allow() on another user's direct messages = false, want true")
+	}
+}
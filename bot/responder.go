@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Responder sends the response of a command back to wherever the command came
+// from. This allows the command handlers to not care whether they were invoked
+// from a message, a slash command or anything else. Replies are abandoned once
+// the context is done.
+type Responder interface {
+
+	// Reply sends the given embed as a response to the command.
+	Reply(ctx context.Context, embed *discordgo.MessageEmbed) error
+
+	// ReplyFile sends the given file as a response to the command, the embed
+	// is optional and will be sent alongside the file if given.
+	ReplyFile(ctx context.Context, name string, r io.Reader, embed *discordgo.MessageEmbed) error
+
+	// ReplyText sends the given plain text as a response to the command. The
+	// text can't mention anyone, so user provided text can't be used to ping
+	// the whole server.
+	ReplyText(ctx context.Context, content string) error
+}
+
+// noMentions keeps the messages sent by the bot from pinging anyone.
+var noMentions = &discordgo.MessageAllowedMentions{}
+
+// channelResponder responds to commands by sending messages to a channel, this
+// is used for the prefix commands.
+type channelResponder struct {
+	session   *discordgo.Session
+	channelID string
+}
+
+func (r *channelResponder) Reply(ctx context.Context, embed *discordgo.MessageEmbed) error {
+	_, err := r.session.ChannelMessageSendEmbed(r.channelID, embed, discordgo.WithContext(ctx))
+	return err
+}
+
+func (r *channelResponder) ReplyFile(ctx context.Context, name string, f io.Reader, embed *discordgo.MessageEmbed) error {
+	_, err := r.session.ChannelMessageSendComplex(r.channelID, &discordgo.MessageSend{
+		Embed: embed,
+		Files: []*discordgo.File{{Name: name, Reader: f}},
+	}, discordgo.WithContext(ctx))
+	return err
+}
+
+func (r *channelResponder) ReplyText(ctx context.Context, content string) error {
+	_, err := r.session.ChannelMessageSendComplex(r.channelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: noMentions,
+	}, discordgo.WithContext(ctx))
+	return err
+}
+
+// interactionResponder responds to commands invoked through an interaction,
+// the first reply will fill in the deferred interaction response and anything
+// after that will be sent as a follow up message.
+type interactionResponder struct {
+	session     *discordgo.Session
+	interaction *discordgo.Interaction
+
+	mu        sync.Mutex
+	responded bool
+}
+
+// deferResponse acknowledges the interaction, letting the user know the
+// command is being worked on. Discord requires this to happen within 3 seconds.
+func (r *interactionResponder) deferResponse() error {
+	return r.session.InteractionRespond(r.interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+}
+
+func (r *interactionResponder) Reply(ctx context.Context, embed *discordgo.MessageEmbed) error {
+	return r.send(ctx, "", []*discordgo.MessageEmbed{embed}, nil)
+}
+
+func (r *interactionResponder) ReplyText(ctx context.Context, content string) error {
+	return r.send(ctx, content, nil, nil)
+}
+
+func (r *interactionResponder) ReplyFile(ctx context.Context, name string, f io.Reader, embed *discordgo.MessageEmbed) error {
+	var embeds []*discordgo.MessageEmbed
+	if embed != nil {
+		embeds = []*discordgo.MessageEmbed{embed}
+	}
+	return r.send(ctx, "", embeds, []*discordgo.File{{Name: name, Reader: f}})
+}
+
+// replyEphemeral answers with a message only the user can see. A deferred
+// response can't be made ephemeral once sent, so it is deleted and the
+// message is sent as a follow up instead.
+func (r *interactionResponder) replyEphemeral(ctx context.Context, embed *discordgo.MessageEmbed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.responded {
+		if err := r.session.InteractionResponseDelete(r.interaction, discordgo.WithContext(ctx)); err != nil {
+			return err
+		}
+		r.responded = true
+	}
+	_, err := r.session.FollowupMessageCreate(r.interaction, true, &discordgo.WebhookParams{
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		AllowedMentions: noMentions,
+		Flags:           discordgo.MessageFlagsEphemeral,
+	}, discordgo.WithContext(ctx))
+	return err
+}
+
+func (r *interactionResponder) send(
+	ctx context.Context,
+	content string,
+	embeds []*discordgo.MessageEmbed,
+	files []*discordgo.File,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.responded {
+		edit := &discordgo.WebhookEdit{
+			Embeds:          &embeds,
+			Files:           files,
+			AllowedMentions: noMentions,
+		}
+		if content != "" {
+			edit.Content = &content
+		}
+		_, err := r.session.InteractionResponseEdit(r.interaction, edit, discordgo.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		r.responded = true
+		return nil
+	}
+
+	_, err := r.session.FollowupMessageCreate(r.interaction, true, &discordgo.WebhookParams{
+		Content:         content,
+		Embeds:          embeds,
+		Files:           files,
+		AllowedMentions: noMentions,
+	}, discordgo.WithContext(ctx))
+	return err
+}
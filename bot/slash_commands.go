@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/metrics"
+)
+
+const (
+	slashCommandPrefix = "/"
+
+	// Discord limits the description of application commands to 100
+	// characters.
+	slashCommandDescriptionMaxCharacters = 100
+)
+
+func stringOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        name,
+		Description: description,
+		Required:    required,
+	}
+}
+
+// registerApplicationCommands registers every command on the bot as an
+// application (slash) command. If a guild ID is configured, the commands
+// will only be registered on that guild, otherwise they are global.
+func (b *Bot) registerApplicationCommands(s *discordgo.Session) error {
+	app, err := s.Application("@me")
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch the bot's application")
+	}
+
+	appCommands := b.applicationCommands()
+	guildID := b.config.Discord.SlashCommands.GuildID
+	if _, err := s.ApplicationCommandBulkOverwrite(app.ID, guildID, appCommands); err != nil {
+		return err
+	}
+
+	zap.L().Info(
+		"registered application commands",
+		zap.Int("commands", len(appCommands)),
+		zap.String("guild_id", guildID),
+	)
+	return nil
+}
+
+// applicationCommands builds the application commands definitions out of the
+// bot's commands, sorted by name so they are registered the same every time.
+func (b *Bot) applicationCommands() []*discordgo.ApplicationCommand {
+	commandNames := make([]string, 0, len(b.commands))
+	for name := range b.commands {
+		commandNames = append(commandNames, name)
+	}
+	sort.Strings(commandNames)
+
+	dmPermission := false
+	appCommands := make([]*discordgo.ApplicationCommand, 0, len(commandNames))
+	for _, name := range commandNames {
+		cmd := b.commands[name]
+		description := cmd.helpText
+		if cmd.alias != "" {
+			target, ok := b.commands[cmd.alias]
+			if !ok {
+				continue
+			}
+			description = fmt.Sprintf("Alias for %s%s", slashCommandPrefix, cmd.alias)
+			cmd = target
+		}
+
+		// maintainer commands are only available as prefix commands, so
+		// they never show up for regular users.
+		if cmd.botAdminOnly {
+			continue
+		}
+
+		appCommands = append(appCommands, &discordgo.ApplicationCommand{
+			Type:         discordgo.ChatApplicationCommand,
+			Name:         name,
+			Description:  slashCommandDescription(description),
+			Options:      cmd.options,
+			DMPermission: &dmPermission,
+		})
+	}
+	return appCommands
+}
+
+// slashCommandDescription makes sure the help text of a command fits in the
+// description of an application command by only using its first line, and
+// truncating it if needed.
+func slashCommandDescription(helpText string) string {
+	description := strings.TrimSpace(strings.SplitN(helpText, "\n", 2)[0])
+	if r := []rune(description); len(r) > slashCommandDescriptionMaxCharacters {
+		description = string(r[:slashCommandDescriptionMaxCharacters-3]) + "..."
+	}
+	return description
+}
+
+// This function will be called (due to AddHandler above) every time an
+// interaction is created, which happens when a user runs a slash command.
+func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	// slash commands are only registered for guilds, so we should always get
+	// a member back. Just in case, ignore anything else.
+	if i.Member == nil || i.Member.User == nil {
+		return
+	}
+
+	logger := zap.L()
+	data := i.ApplicationCommandData()
+	botCmd, ok := b.commands[data.Name]
+	if !ok {
+		return
+	}
+	if botCmd.alias != "" {
+		botCmd, ok = b.commands[botCmd.alias]
+		if !ok {
+			return
+		}
+	}
+	if botCmd.botAdminOnly {
+		return
+	}
+
+	guild, err := s.State.Guild(i.GuildID)
+	if err != nil {
+		logger.Error(
+			"error retrieving the guild",
+			zap.Error(err),
+			zap.String("guild_id", i.GuildID),
+		)
+		return
+	}
+
+	guildSettings, err := b.getOrCreateGuildSettings(guild)
+	if err != nil {
+		logger.Error(
+			"error retrieving the guild's config",
+			zap.Error(err),
+			zap.String("guild_name", guild.Name),
+			zap.String("guild_id", guild.ID),
+		)
+		return
+	}
+
+	// Slash commands show up everywhere, so let the user know when the
+	// guild does not want the bot to respond on this channel.
+	if len(guildSettings.ListeningChannels) > 0 && !inListenChannels(i.ChannelID, guildSettings.ListeningChannels) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Rotom-B is not listening to commands on this channel.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			logger.Error("failed to respond to interaction", zap.Error(err))
+		}
+		return
+	}
+
+	if isIgnored(guildSettings, botCmd, i.ChannelID, i.Member.User.ID, i.Member.Roles) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Rotom-B is ignoring commands from you or on this channel.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			logger.Error("failed to respond to interaction", zap.Error(err))
+		}
+		return
+	}
+
+	// Slash commands can't be hidden per guild, so let the user know the
+	// command is disabled.
+	if commandDisabled(guildSettings, data.Name, botCmd, i.ChannelID) {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("The %s command is disabled on this channel.", data.Name),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			logger.Error("failed to respond to interaction", zap.Error(err))
+		}
+		return
+	}
+
+	// Map the typed options back into the arguments list the prefix commands
+	// get, in the order the command declares them.
+	args := make([]string, 0, len(data.Options))
+	for _, opt := range botCmd.options {
+		for _, given := range data.Options {
+			if given.Name == opt.Name {
+				args = append(args, strings.Fields(given.StringValue())...)
+			}
+		}
+	}
+
+	reqID := atomic.AddUint64(&b.requestsServed, 1)
+	metrics.RequestsServed.Inc()
+	logger.Info(
+		"processing slash command",
+		zap.Uint64("request_id", reqID),
+		zap.String("command", data.Name),
+		zap.Strings("args", args),
+		zap.String("user", i.Member.User.String()),
+		zap.String("guild_id", i.GuildID),
+	)
+
+	env := &commandEnvironment{
+		args:          args,
+		command:       data.Name,
+		commandPrefix: slashCommandPrefix,
+		reqID:         reqID,
+		user:          i.Member.User,
+		guildID:       i.GuildID,
+		channelID:     i.ChannelID,
+		guildSettings: guildSettings,
+		session:       s,
+		ctx:           context.Background(),
+		bot:           b,
+	}
+
+	// Interactions can always be answered with ephemeral messages, so let
+	// the user know every time.
+	if wait, ok := b.allowCommand(botCmd, env); !ok {
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{b.slowDownEmbed(wait)},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			logger.Error("failed to respond to interaction", zap.Error(err))
+		}
+		return
+	}
+
+	// Discord requires us to acknowledge the interaction within 3 seconds,
+	// so do it before running the command. The responder will fill in the
+	// response once the command is done.
+	responder := &interactionResponder{session: s, interaction: i.Interaction}
+	if err := responder.deferResponse(); err != nil {
+		logger.Error(
+			"failed to respond to interaction",
+			zap.Uint64("request_id", reqID),
+			zap.Error(err),
+		)
+		return
+	}
+	env.responder = responder
+	b.dispatch(botCmd, env)
+}
@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+// timezoneAbbreviations maps the abbreviations people usually type to the
+// timezone they refer to. Both the standard and daylight saving abbreviations
+// map to the same zone, so "8pm EST" means 8pm in New York year round, which
+// is what people mean when they write it.
+var timezoneAbbreviations = map[string]string{
+	"utc":  "UTC",
+	"gmt":  "UTC",
+	"est":  "America/New_York",
+	"edt":  "America/New_York",
+	"et":   "America/New_York",
+	"cst":  "America/Chicago",
+	"cdt":  "America/Chicago",
+	"ct":   "America/Chicago",
+	"mst":  "America/Denver",
+	"mdt":  "America/Denver",
+	"mt":   "America/Denver",
+	"pst":  "America/Los_Angeles",
+	"pdt":  "America/Los_Angeles",
+	"pt":   "America/Los_Angeles",
+	"bst":  "Europe/London",
+	"cet":  "Europe/Paris",
+	"cest": "Europe/Paris",
+	"jst":  "Asia/Tokyo",
+	"aest": "Australia/Sydney",
+	"aedt": "Australia/Sydney",
+}
+
+var (
+	isoDateRegex   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	shortDateRegex = regexp.MustCompile(`^\d{1,2}/\d{1,2}$`)
+
+	// clockLayouts are the time of day formats accepted, matched against the
+	// lower cased input.
+	clockLayouts = []string{"15:04", "3pm", "3:04pm"}
+)
+
+// loadTimezone returns the timezone with the given IANA name or common
+// abbreviation, i.e. America/New_York or EST.
+func loadTimezone(name string) (*time.Location, bool) {
+	if zone, ok := timezoneAbbreviations[strings.ToLower(name)]; ok {
+		name = zone
+	}
+
+	// only IANA names are accepted past this point, time.LoadLocation also
+	// accepts "Local", which depends on the machine the bot runs on.
+	if name != "UTC" && !strings.Contains(name, "/") {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// guildLocation returns the guild's timezone, or UTC if it has none.
+func guildLocation(settings *repository.GuildSettings) *time.Location {
+	if loc, ok := loadTimezone(settings.Timezone); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// parseStartTime parses a date and time from the beginning of the args, in
+// the format [today|tomorrow|YYYY-MM-DD|MM/DD] <time> [timezone], where time
+// can be 20:00, 8pm or 8:30pm. The time is in the given timezone unless one
+// is given, and without a date it is the next time the clock reads that time.
+// It returns the time and the args after it.
+func parseStartTime(args []string, now time.Time, loc *time.Location) (time.Time, []string, error) {
+	i := 0
+	next := func() string {
+		if i < len(args) {
+			return strings.ToLower(args[i])
+		}
+		return ""
+	}
+
+	// relative dates are worked out on the given timezone, and combined with
+	// the time on whichever timezone the time is in.
+	year, month, day := now.In(loc).Date()
+	dateGiven, yearGiven := true, true
+	switch arg := next(); {
+	case arg == "today":
+		i++
+	case arg == "tomorrow":
+		year, month, day = now.In(loc).AddDate(0, 0, 1).Date()
+		i++
+	case isoDateRegex.MatchString(arg):
+		d, err := time.Parse("2006-01-02", arg)
+		if err != nil {
+			return time.Time{}, nil, invalidStartTime(args[i])
+		}
+		year, month, day = d.Date()
+		i++
+	case shortDateRegex.MatchString(arg):
+		d, err := time.Parse("1/2", arg)
+		if err != nil {
+			return time.Time{}, nil, invalidStartTime(args[i])
+		}
+		_, month, day = d.Date()
+		yearGiven = false
+		i++
+	default:
+		dateGiven = false
+	}
+
+	clock := next()
+	if clock == "" {
+		return time.Time{}, nil, botError{
+			title:   "Validation Error",
+			details: "A start time is required, i.e. `8pm`, `20:00` or `tomorrow 8:30pm EST`",
+		}
+	}
+	i++
+
+	// "8 pm" is the same as "8pm"
+	if suffix := next(); suffix == "am" || suffix == "pm" {
+		clock += suffix
+		i++
+	}
+
+	parsed, ok := parseClock(clock)
+	if !ok {
+		return time.Time{}, nil, invalidStartTime(clock)
+	}
+
+	if i < len(args) {
+		if l, ok := loadTimezone(args[i]); ok {
+			loc = l
+			i++
+		}
+	}
+
+	start := time.Date(year, month, day, parsed.Hour(), parsed.Minute(), 0, 0, loc)
+	if !dateGiven && !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	if !yearGiven && !start.After(now) {
+		start = start.AddDate(1, 0, 0)
+	}
+	return start, args[i:], nil
+}
+
+func invalidStartTime(arg string) error {
+	return botError{
+		title:   "Validation Error",
+		details: fmt.Sprintf("%q is not a valid date or time, try `8pm`, `20:00` or `2020-12-24 8:30pm EST`", arg),
+	}
+}
+
+// formatGuildTime formats the time on the guild's timezone.
+func formatGuildTime(t time.Time, settings *repository.GuildSettings) string {
+	return t.In(guildLocation(settings)).Format("Mon Jan 2, 3:04 PM MST")
+}
+
+// parseQuietHours parses a time range like 23:00-07:00 or 11pm-7am, and
+// returns it in the 24 hour format it is stored in.
+func parseQuietHours(s string) (string, bool) {
+	parts := strings.Split(strings.ToLower(s), "-")
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	clocks := make([]string, len(parts))
+	for i, part := range parts {
+		t, ok := parseClock(part)
+		if !ok {
+			return "", false
+		}
+		clocks[i] = t.Format("15:04")
+	}
+	if clocks[0] == clocks[1] {
+		return "", false
+	}
+	return clocks[0] + "-" + clocks[1], true
+}
+
+// inQuietHours checks whether the time falls in the guild's quiet hours.
+// Ranges that wrap around midnight, like 23:00-07:00, are supported.
+func inQuietHours(settings *repository.GuildSettings, t time.Time) bool {
+	if settings.QuietHours == "" {
+		return false
+	}
+	parts := strings.Split(settings.QuietHours, "-")
+	if len(parts) != 2 {
+		return false
+	}
+	start, okStart := parseClock(parts[0])
+	end, okEnd := parseClock(parts[1])
+	if !okStart || !okEnd {
+		return false
+	}
+
+	local := t.In(guildLocation(settings))
+	now := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// parseClock parses a time of day in any of the clockLayouts.
+func parseClock(s string) (time.Time, bool) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
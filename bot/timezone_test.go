@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseStartTime(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	// Thursday, 6pm UTC on new year's eve
+	now := time.Date(2020, 12, 31, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		args     string
+		loc      *time.Location
+		want     time.Time
+		wantRest []string
+		wantErr  bool
+	}{
+		{
+			name: "time later today",
+			args: "8pm",
+			want: time.Date(2020, 12, 31, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "24 hour time",
+			args: "20:15",
+			want: time.Date(2020, 12, 31, 20, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "split am pm suffix",
+			args:     "8 pm bring balls",
+			want:     time.Date(2020, 12, 31, 20, 0, 0, 0, time.UTC),
+			wantRest: []string{"bring", "balls"},
+		},
+		{
+			name: "split uppercase suffix with minutes",
+			args: "8:30 PM",
+			want: time.Date(2020, 12, 31, 20, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "time already passed rolls over to tomorrow",
+			args: "5pm",
+			want: time.Date(2021, 1, 1, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "current time rolls over to tomorrow",
+			args: "18:00",
+			want: time.Date(2021, 1, 1, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "today keeps a time that already passed",
+			args: "today 5pm",
+			want: time.Date(2020, 12, 31, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "tomorrow",
+			args: "Tomorrow 8:30pm",
+			want: time.Date(2021, 1, 1, 20, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "iso date",
+			args: "2021-02-03 10am",
+			want: time.Date(2021, 2, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "short date later this year",
+			args: "12/31 11pm",
+			want: time.Date(2020, 12, 31, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "short date rolls over to next year",
+			args: "12/24 8pm",
+			want: time.Date(2021, 12, 24, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "short date early next year",
+			args: "1/2 8pm",
+			want: time.Date(2021, 1, 2, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone abbreviation overrides the guild's timezone",
+			args:     "8pm EST raid",
+			want:     time.Date(2020, 12, 31, 20, 0, 0, 0, newYork),
+			wantRest: []string{"raid"},
+		},
+		{
+			name: "iana timezone overrides the guild's timezone",
+			args: "tomorrow 8 pm Asia/Tokyo",
+			loc:  newYork,
+			want: time.Date(2021, 1, 1, 20, 0, 0, 0, tokyo),
+		},
+		{
+			name: "relative dates use the guild's timezone",
+			args: "8pm",
+			loc:  tokyo,
+			// it's already 3am on new year's day in Tokyo
+			want: time.Date(2021, 1, 1, 20, 0, 0, 0, tokyo),
+		},
+		{
+			name:     "unknown timezone is left in the args",
+			args:     "8pm Mars/Olympus",
+			want:     time.Date(2020, 12, 31, 20, 0, 0, 0, time.UTC),
+			wantRest: []string{"Mars/Olympus"},
+		},
+		{
+			name:    "missing time",
+			args:    "tomorrow",
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			args:    "25pm",
+			wantErr: true,
+		},
+		{
+			name:    "invalid short date",
+			args:    "13/45 8pm",
+			wantErr: true,
+		},
+		{
+			name:    "invalid iso date",
+			args:    "2021-02-30 8pm",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			got, rest, err := parseStartTime(strings.Fields(tt.args), now, loc)
+			if tt.wantErr {
+				if _, ok := err.(botError); !ok {
+					t.Fatalf("parseStartTime() error = %v, want a botError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStartTime() error = %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location().String() != tt.want.Location().String() {
+				t.Errorf("parseStartTime() = %s, want %s", got, tt.want)
+			}
+			if len(rest) != 0 || len(tt.wantRest) != 0 {
+				if !reflect.DeepEqual(rest, tt.wantRest) {
+					t.Errorf("parseStartTime() rest = %q, want %q", rest, tt.wantRest)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"23:00-07:00", "23:00-07:00", true},
+		{"11pm-7am", "23:00-07:00", true},
+		{"11PM-7:30AM", "23:00-07:30", true},
+		{"9:00 - 17:00", "09:00-17:00", true},
+		{"22:00-10pm", "", false},
+		{"23:00", "", false},
+		{"1am-2am-3am", "", false},
+		{"late-7am", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := parseQuietHours(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseQuietHours(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2021, 1, 1, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		quietHours string
+		timezone   string
+		t          time.Time
+		want       bool
+	}{
+		{name: "no quiet hours", t: at(3, 0)},
+		{name: "invalid quiet hours", quietHours: "night", t: at(3, 0)},
+		{name: "inside", quietHours: "09:00-17:00", t: at(12, 0), want: true},
+		{name: "on the start", quietHours: "09:00-17:00", t: at(9, 0), want: true},
+		{name: "on the end", quietHours: "09:00-17:00", t: at(17, 0)},
+		{name: "before", quietHours: "09:00-17:00", t: at(8, 59)},
+		{name: "wrapped before midnight", quietHours: "23:00-07:00", t: at(23, 30), want: true},
+		{name: "wrapped after midnight", quietHours: "23:00-07:00", t: at(3, 0), want: true},
+		{name: "wrapped on the end", quietHours: "23:00-07:00", t: at(7, 0)},
+		{name: "wrapped outside", quietHours: "23:00-07:00", t: at(12, 0)},
+		{
+			// 9pm in New York
+			name:       "guild timezone outside",
+			quietHours: "23:00-07:00",
+			timezone:   "America/New_York",
+			t:          at(2, 0),
+		},
+		{
+			// midnight in New York
+			name:       "guild timezone inside",
+			quietHours: "23:00-07:00",
+			timezone:   "America/New_York",
+			t:          at(5, 0),
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &repository.GuildSettings{QuietHours: tt.quietHours, Timezone: tt.timezone}
+			if got := inQuietHours(settings, tt.t); got != tt.want {
+				t.Errorf("inQuietHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
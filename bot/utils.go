@@ -55,3 +55,12 @@ func isExcludedBall(ball *repository.PokeBall) bool {
 	}
 	return false
 }
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
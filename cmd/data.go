@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/repository"
+)
+
+func init() {
+	dataCmd := &cobra.Command{
+		Use:   "data",
+		Short: "Tools to work with the bot's data files",
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Cross-check the data files and report any problems found",
+		Run:   runDataValidateCommand,
+	}
+	validateCmd.Flags().Bool("hide-warnings", false, "Only report problems that are errors")
+
+	dataCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(dataCmd)
+}
+
+func runDataValidateCommand(cmd *cobra.Command, _ []string) {
+	logger := zap.L()
+	hideWarnings, err := cmd.Flags().GetBool("hide-warnings")
+	if err != nil {
+		logger.Fatal("failed to read hide-warnings flag", zap.Error(err))
+	}
+
+	problems, err := repository.ValidateData()
+	if err != nil {
+		logger.Fatal("failed to validate data", zap.Error(err))
+	}
+
+	var errorCount, warningCount int
+	for _, p := range problems {
+		if p.Warning {
+			warningCount++
+			if hideWarnings {
+				continue
+			}
+		} else {
+			errorCount++
+		}
+		fmt.Println(p)
+	}
+
+	fmt.Printf("\nFound %d errors and %d warnings\n", errorCount, warningCount)
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/caquillo07/rotom-bot/bot"
+	"github.com/caquillo07/rotom-bot/conf"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:     "query <command> [arguments]",
+		Short:   "Run a bot command offline and print its response",
+		Example: "den-bot query catch charizard gmax lux",
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runQueryCommand,
+	}
+	cmd.Flags().String("format", bot.QueryFormatText, "Output format, either text or json")
+	rootCmd.AddCommand(cmd)
+}
+
+func runQueryCommand(cmd *cobra.Command, args []string) {
+	logger := zap.L()
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		logger.Fatal("failed to read format flag", zap.Error(err))
+	}
+
+	config, err := conf.LoadConfig(viper.GetViper())
+	if err != nil {
+		logger.Fatal("failed to load config", zap.Error(err))
+	}
+
+	b := bot.NewBot(config)
+	if err := b.Query(os.Stdout, format, args); err != nil {
+		// the command's own errors were already printed as its response
+		if err != bot.ErrQueryFailed {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -18,6 +19,17 @@ type Config struct {
 		PlayingStatus    string
 		InviteURL        string
 		SupportServerURL string
+
+		// SlashCommands configures the registration of the bot commands as
+		// Discord application (slash) commands.
+		SlashCommands struct {
+			Enable bool
+
+			// GuildID registers the commands only on the given guild instead
+			// of globally. Useful while testing, since global commands can
+			// take up to an hour to show up.
+			GuildID string
+		}
 	}
 
 	DBL struct {
@@ -32,6 +44,84 @@ type Config struct {
 		ErrorEmbedColor   int
 		WarningEmbedColor int
 		FooterIconURL     string
+
+		// FuzzyMatchThreshold is how similar, from 0 to 1, the closest match
+		// to a misspelled Pokémon, ball, type or nature must be to be used
+		// in place of it. Zero disables it, and only suggestions are shown.
+		FuzzyMatchThreshold float64
+
+		// Maintainers is the list of Discord user IDs allowed to run the
+		// maintainer commands, regardless of the server they are on.
+		Maintainers []string
+
+		// WelcomeMessage sends a welcome and setup message to the system
+		// channel of the guilds the bot joins.
+		WelcomeMessage bool
+
+		// LeftGuildRetentionDays is how many days the data of a guild the bot
+		// was removed from is kept before being deleted. Zero keeps it
+		// forever.
+		LeftGuildRetentionDays int
+
+		// MaxTagsPerGuild is how many tags each guild can create
+		MaxTagsPerGuild int
+	}
+
+	// RateLimit configures the per guild command limits, on top of the per
+	// user cooldowns of each command.
+	RateLimit struct {
+		// GuildRate is how many commands per second a guild gets back
+		GuildRate float64
+
+		// GuildBurst is the most commands a guild can send at once, zero
+		// disables the guild limits.
+		GuildBurst int
+	}
+
+	// Executor configures how the commands are run
+	Executor struct {
+		// Workers is how many commands can run at the same time
+		Workers int
+
+		// QueueSize is how many commands can wait for a free worker, any
+		// commands over it are rejected.
+		QueueSize int
+
+		// CommandTimeout is how long a command can run for
+		CommandTimeout time.Duration
+
+		// ShutdownTimeout is how long to wait for the running commands to
+		// finish when shutting down.
+		ShutdownTimeout time.Duration
+	}
+
+	// Raids configures the Max Raid lobbies hosted with the host command
+	Raids struct {
+		// LobbyDuration is how long a lobby stays open before it is closed
+		LobbyDuration time.Duration
+
+		// ReminderMinutes is how many minutes before a scheduled raid starts
+		// the users that RSVP'd are pinged, unless the host picks otherwise.
+		ReminderMinutes int
+
+		// MaxSubscriptionsPerUser is how many Pokémon each user can get raid
+		// notifications for on each guild.
+		MaxSubscriptionsPerUser int
+	}
+
+	// Announcements configures the update announcements the maintainers
+	// broadcast to the guilds that opted in to them.
+	Announcements struct {
+		// Interval is how long to wait between each guild's announcement,
+		// to stay well under Discord's rate limits.
+		Interval time.Duration
+	}
+
+	// HTTP configures the optional HTTP listener, which exposes the bot's
+	// Prometheus metrics on /metrics.
+	HTTP struct {
+		Enable  bool
+		Address string
 	}
 
 	// Config provides database configuration
@@ -57,6 +147,15 @@ func InitViper(configFile string) {
 	viper.SetConfigFile(configFile)
 
 	// Default settings
+	viper.SetDefault("bot.maxTagsPerGuild", 50)
+	viper.SetDefault("executor.workers", 50)
+	viper.SetDefault("executor.queueSize", 500)
+	viper.SetDefault("executor.commandTimeout", 30*time.Second)
+	viper.SetDefault("executor.shutdownTimeout", 30*time.Second)
+	viper.SetDefault("raids.lobbyDuration", 15*time.Minute)
+	viper.SetDefault("raids.reminderMinutes", 15)
+	viper.SetDefault("raids.maxSubscriptionsPerUser", 10)
+	viper.SetDefault("announcements.interval", 2*time.Second)
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv() // read in environment variables that match
 	if err := viper.ReadInConfig(); err != nil {
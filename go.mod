@@ -3,12 +3,14 @@ module github.com/caquillo07/rotom-bot
 go 1.14
 
 require (
-	github.com/bwmarrin/discordgo v0.21.1
+	github.com/bwmarrin/discordgo v0.27.1
 	github.com/golang-migrate/migrate/v4 v4.12.2
 	github.com/jinzhu/gorm v1.9.15
 	github.com/kubastick/dblgo v0.2.1
 	github.com/lib/pq v1.3.0
 	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.7.1
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/viper v1.4.0
 	go.uber.org/zap v1.15.0
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "rotomb"
+
+// Outcomes of a command execution
+const (
+	OutcomeSuccess       = "success"
+	OutcomeBotError      = "bot_error"
+	OutcomeInternalError = "internal_error"
+	OutcomeDenied        = "denied"
+	OutcomePanic         = "panic"
+	OutcomeRateLimited   = "rate_limited"
+	OutcomeRejected      = "rejected"
+	OutcomeTimeout       = "timeout"
+)
+
+// Types of errors communicated back to the users
+const (
+	ErrorTypeBot      = "bot"
+	ErrorTypeInternal = "internal"
+)
+
+var (
+
+	// CommandsExecuted counts the commands executed by name and outcome
+	CommandsExecuted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_executed_total",
+		Help:      "Number of commands executed, by command name and outcome.",
+	}, []string{"command", "outcome"})
+
+	// CommandDuration tracks how long the command handlers take to run
+	CommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time it takes the command handlers to run, by command name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	// CommandErrors counts the errors communicated back to the users, split
+	// between public bot errors and internal errors.
+	CommandErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "command_errors_total",
+		Help:      "Number of command errors sent to users, by error type.",
+	}, []string{"type"})
+
+	// PanicsRecovered counts the panics recovered while handling commands
+	PanicsRecovered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_recovered_total",
+		Help:      "Number of panics recovered while handling commands.",
+	})
+
+	// GuildSettingsCache counts the guild settings cache lookups by result,
+	// either hit or miss, to calculate the cache hit rate.
+	GuildSettingsCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "guild_settings_cache_lookups_total",
+		Help:      "Number of guild settings cache lookups, by result.",
+	}, []string{"result"})
+
+	// ShardConnected is 1 when the shard is connected to the gateway, and 0
+	// otherwise.
+	ShardConnected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "shard_connected",
+		Help:      "Whether the shard is connected to the Discord gateway.",
+	}, []string{"shard"})
+
+	// RequestsServed counts every request the bot has served
+	RequestsServed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_served_total",
+		Help:      "Number of requests served by the bot.",
+	})
+)
@@ -0,0 +1,21 @@
+-- max raid lobbies hosted on each guild
+CREATE TABLE raid_lobby (
+  id SERIAL PRIMARY KEY,
+  guild_id TEXT NOT NULL,
+  channel_id TEXT NOT NULL,
+  message_id TEXT NOT NULL UNIQUE,
+  host_id TEXT NOT NULL,
+  den TEXT,
+  pokemon TEXT,
+  code TEXT,
+  gigantamax BOOLEAN NOT NULL DEFAULT FALSE,
+  shiny BOOLEAN NOT NULL DEFAULT FALSE,
+  challengers TEXT ARRAY,
+  queue TEXT ARRAY,
+  expires_at TIMESTAMPTZ NOT NULL,
+  closed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX raid_lobby_open_idx ON raid_lobby (expires_at) WHERE closed_at IS NULL;
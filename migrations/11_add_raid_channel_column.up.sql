@@ -0,0 +1 @@
+alter table guild_settings add column raid_channel_id TEXT;
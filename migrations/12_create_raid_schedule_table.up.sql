@@ -0,0 +1,18 @@
+-- raids announced ahead of time, with the users that RSVP'd to them
+CREATE TABLE raid_schedule (
+  id SERIAL PRIMARY KEY,
+  guild_id TEXT NOT NULL,
+  channel_id TEXT NOT NULL,
+  message_id TEXT NOT NULL UNIQUE,
+  host_id TEXT NOT NULL,
+  title TEXT NOT NULL,
+  starts_at TIMESTAMPTZ NOT NULL,
+  remind_minutes INT NOT NULL,
+  rsvps TEXT ARRAY,
+  reminded_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX raid_schedule_upcoming_idx ON raid_schedule (starts_at) WHERE cancelled_at IS NULL;
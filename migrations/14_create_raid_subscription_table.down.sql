@@ -0,0 +1 @@
+DROP TABLE raid_subscription;
@@ -0,0 +1,14 @@
+-- pokemon users want to be pinged about when someone hosts a raid for them
+CREATE TABLE raid_subscription (
+  id SERIAL PRIMARY KEY,
+  guild_id TEXT NOT NULL,
+  user_id TEXT NOT NULL,
+  pokemon TEXT NOT NULL,
+  shiny BOOLEAN NOT NULL DEFAULT FALSE,
+  gigantamax BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  UNIQUE (guild_id, user_id, pokemon, shiny, gigantamax)
+);
+
+CREATE INDEX raid_subscription_pokemon_idx ON raid_subscription (guild_id, pokemon);
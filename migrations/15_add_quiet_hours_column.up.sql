@@ -0,0 +1 @@
+alter table guild_settings add column quiet_hours TEXT;
@@ -0,0 +1 @@
+alter table guild_settings drop column announcements_channel_id;
@@ -0,0 +1 @@
+DROP TABLE announcement_delivery;
@@ -0,0 +1,14 @@
+-- the changelog announcements sent to each guild, along with the ones that
+-- failed to be delivered
+CREATE TABLE announcement_delivery (
+  id SERIAL PRIMARY KEY,
+  guild_id TEXT NOT NULL,
+  version TEXT NOT NULL,
+  channel_id TEXT NOT NULL,
+  message_id TEXT,
+  error TEXT,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  UNIQUE (guild_id, version)
+);
@@ -0,0 +1 @@
+DROP INDEX raid_lobby_open_host_idx;
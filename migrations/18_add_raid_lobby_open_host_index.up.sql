@@ -0,0 +1,9 @@
+-- each host can only have one open lobby per guild, any duplicates opened
+-- before the index existed are closed, keeping the newest one.
+UPDATE raid_lobby l SET closed_at = NOW()
+WHERE closed_at IS NULL AND EXISTS (
+  SELECT 1 FROM raid_lobby o
+  WHERE o.guild_id = l.guild_id AND o.host_id = l.host_id AND o.closed_at IS NULL AND o.id > l.id
+);
+
+CREATE UNIQUE INDEX raid_lobby_open_host_idx ON raid_lobby (guild_id, host_id) WHERE closed_at IS NULL;
@@ -0,0 +1 @@
+alter table guild_settings drop column left_at;
@@ -0,0 +1 @@
+alter table guild_settings add column left_at TIMESTAMPTZ;
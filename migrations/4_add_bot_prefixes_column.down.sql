@@ -0,0 +1,4 @@
+alter table guild_settings add column bot_prefix TEXT;
+update guild_settings set bot_prefix = coalesce(bot_prefixes[1], '$');
+alter table guild_settings alter column bot_prefix set not null;
+alter table guild_settings drop column bot_prefixes;
@@ -0,0 +1,3 @@
+alter table guild_settings add column bot_prefixes TEXT ARRAY;
+update guild_settings set bot_prefixes = ARRAY[bot_prefix];
+alter table guild_settings drop column bot_prefix;
@@ -0,0 +1 @@
+alter table guild_settings drop column disabled_commands;
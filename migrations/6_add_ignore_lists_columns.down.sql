@@ -0,0 +1,3 @@
+alter table guild_settings drop column ignored_channels;
+alter table guild_settings drop column ignored_users;
+alter table guild_settings drop column ignored_roles;
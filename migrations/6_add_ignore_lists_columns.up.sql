@@ -0,0 +1,3 @@
+alter table guild_settings add column ignored_channels jsonb;
+alter table guild_settings add column ignored_users TEXT ARRAY;
+alter table guild_settings add column ignored_roles TEXT ARRAY;
@@ -0,0 +1,12 @@
+-- friend codes and in game names shared by users
+CREATE TABLE user_profile (
+  id SERIAL PRIMARY KEY,
+  user_id TEXT NOT NULL UNIQUE,
+  friend_code TEXT,
+  ign TEXT,
+  game TEXT,
+  global BOOLEAN NOT NULL DEFAULT FALSE,
+  guild_ids TEXT ARRAY,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
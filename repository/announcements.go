@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+)
+
+// AnnouncementDelivery records the announcement of a version sent to a guild,
+// or why it could not be sent.
+type AnnouncementDelivery struct {
+
+	// ID internal unique ID
+	ID int `json:"-"`
+
+	// GuildID is the discord ID of the guild the announcement was sent to
+	GuildID string `json:"guildId"`
+
+	// Version is the version of the bot announced
+	Version string `json:"version"`
+
+	// ChannelID is the channel the announcement was sent to
+	ChannelID string `json:"channelId"`
+
+	// MessageID is the announcement message, empty if it failed
+	MessageID string `json:"messageId"`
+
+	// Error is why the announcement could not be sent, empty if it was
+	Error string `json:"error"`
+
+	// DeliveredAt the date the announcement was sent, nil if it failed
+	DeliveredAt *time.Time `json:"deliveredAt"`
+
+	// CreatedAt the date the first attempt was made
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date of the last attempt
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AnnouncementDeliveries returns the deliveries of the version, keyed by guild
+// ID.
+func (r *Repository) AnnouncementDeliveries(version string) (map[string]*AnnouncementDelivery, error) {
+	deliveries := make([]*AnnouncementDelivery, 0)
+	if err := r.db.Where("version = ?", version).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	byGuild := make(map[string]*AnnouncementDelivery, len(deliveries))
+	for _, delivery := range deliveries {
+		byGuild[delivery.GuildID] = delivery
+	}
+	return byGuild, nil
+}
+
+// SaveAnnouncementDelivery creates or updates the delivery.
+func (r *Repository) SaveAnnouncementDelivery(delivery *AnnouncementDelivery) error {
+	return r.db.Save(delivery).Error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+)
+
+const changelogFile = "data/changelog.json"
+
+// ChangelogEntry describes what changed on a version of the bot.
+type ChangelogEntry struct {
+
+	// Version is the version the entry is for, i.e. v1.9.0
+	Version string `json:"-"`
+
+	// Summary is a short description of the release, shown above the list
+	// of changes.
+	Summary string `json:"summary"`
+
+	// Changes are the notable changes of the version, one per line
+	Changes []string `json:"changes"`
+}
+
+// Changelog returns the changelog entry of the given version. The file is
+// read on every call, so entries can be added without restarting the bot.
+func (r *Repository) Changelog(version string) (*ChangelogEntry, error) {
+	entries := make(map[string]*ChangelogEntry)
+	if err := loadJSONInto(changelogFile, &entries); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to load changelog.json: %+v", err)
+	}
+
+	entry, ok := entries[version]
+	if !ok || entry == nil || len(entry.Changes) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	entry.Version = version
+	return entry, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+)
+
+// minSuggestionScore is the lowest similarity a candidate can have to still be
+// considered a suggestion, anything below it is just noise.
+const minSuggestionScore = 0.5
+
+// Match is a candidate found by a fuzzy lookup, along with how similar it is
+// to what was searched for.
+type Match struct {
+	// Name is the candidate's display name
+	Name string
+
+	// Score is how similar the candidate is to the query, from 0 to 1 where
+	// 1 is an exact match.
+	Score float64
+}
+
+// ClosestMatches returns up to n candidates that are the most similar to the
+// given query, best match first. The similarity is calculated with the edit
+// distance between the lower cased query and candidates.
+func ClosestMatches(query string, candidates []string, n int) []Match {
+	query = strings.ToLower(strings.TrimSpace(query))
+	matches := make([]Match, 0, n)
+	for _, c := range candidates {
+		score := similarity(query, strings.ToLower(c))
+		if score < minSuggestionScore {
+			continue
+		}
+		matches = append(matches, Match{Name: c, Score: score})
+	}
+
+	// sort by best score, and alphabetically on ties so we always get the
+	// same suggestions back.
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			return matches[i].Name < matches[j].Name
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// similarity returns how similar two strings are, from 0 to 1, based on their
+// edit distance relative to the longest of the two.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein calculates the minimum number of single character insertions,
+// deletions and substitutions needed to change a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"pikachu", "pikachu", 0},
+		{"pikachu", "pikahcu", 2},
+		{"kitten", "sitting", 3},
+		{"charizard", "charzard", 1},
+		{"flabébé", "flabebe", 2},
+	}
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein([]rune(tt.b), []rune(tt.a)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"eevee", "eevee", 1},
+		{"eevee", "eeve", 0.8},
+		{"abcd", "wxyz", 0},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClosestMatches(t *testing.T) {
+	candidates := []string{"Charizard", "Charmander", "Charmeleon", "Pikachu", "Raichu"}
+	tests := []struct {
+		name  string
+		query string
+		n     int
+		want  []string
+	}{
+		{"exact match", "pikachu", 3, []string{"Pikachu"}},
+		{"best match first", "charmandr", 3, []string{"Charmander", "Charizard", "Charmeleon"}},
+		{"case and spaces are ignored", "  CHARIZARD ", 1, []string{"Charizard"}},
+		{"typo", "charzard", 1, []string{"Charizard"}},
+		{"ties are sorted by name", "charmxxxxx", 2, []string{"Charmander", "Charmeleon"}},
+		{"nothing similar", "zzzzzzzz", 3, []string{}},
+		{"limited to n", "char", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := ClosestMatches(tt.query, candidates, tt.n)
+			got := make([]string, len(matches))
+			for i, m := range matches {
+				got[i] = m.Name
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClosestMatches(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
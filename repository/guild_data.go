@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+)
+
+// guildScopedTables are all the tables holding data that belongs to a guild,
+// keyed by a guild_id column. Any new table storing guild data must be added
+// here, so the data is removed when the guild asks for it. Guild IDs stored
+// anywhere else, like the guilds a user profile is shared with, are not rows
+// of their own, so DeleteGuildData and ExportGuildData handle them one by one.
+var guildScopedTables = []string{
+	"guild_settings",
+	"tag",
+	"raid_lobby",
+	"raid_schedule",
+	"raid_subscription",
+	"announcement_delivery",
+}
+
+// GuildData is everything stored about a guild.
+type GuildData struct {
+	Settings          *GuildSettings          `json:"settings"`
+	Tags              []*Tag                  `json:"tags"`
+	RaidLobbies       []*RaidLobby            `json:"raidLobbies"`
+	RaidSchedules     []*RaidSchedule         `json:"raidSchedules"`
+	RaidSubscriptions []*RaidSubscription     `json:"raidSubscriptions"`
+	Announcements     []*AnnouncementDelivery `json:"announcements"`
+
+	// Profiles are the user profiles shared with the guild
+	Profiles []*UserProfile `json:"profiles"`
+}
+
+// ExportGuildData returns everything stored about the given guild, straight
+// from the database.
+func (r *Repository) ExportGuildData(guildID string) (*GuildData, error) {
+	var settings GuildSettings
+	if err := r.db.Where("guild_id = ?", guildID).Take(&settings).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	tags := make([]*Tag, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	lobbies := make([]*RaidLobby, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("created_at").Find(&lobbies).Error; err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*RaidSchedule, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("starts_at").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]*RaidSubscription, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("user_id, pokemon").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	announcements := make([]*AnnouncementDelivery, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("created_at").Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*UserProfile, 0)
+	if err := r.db.Where("? = ANY(guild_ids)", guildID).Order("user_id").Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+
+	return &GuildData{
+		Settings:          &settings,
+		Tags:              tags,
+		RaidLobbies:       lobbies,
+		RaidSchedules:     schedules,
+		RaidSubscriptions: subscriptions,
+		Announcements:     announcements,
+		Profiles:          profiles,
+	}, nil
+}
+
+// DeleteGuildData removes every record stored about the given guild in a
+// single transaction, and evicts its settings from the cache. User profiles
+// are kept, but stop being shared with the guild.
+func (r *Repository) DeleteGuildData(guildID string) error {
+	err := Transact(context.Background(), r.db, func(ctx context.Context, tx *gorm.DB) error {
+		for _, table := range guildScopedTables {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE guild_id = ?", guildID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec(
+			"UPDATE user_profile SET guild_ids = array_remove(guild_ids, ?) WHERE ? = ANY(guild_ids)",
+			guildID, guildID,
+		).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	r.EvictGuildSettings(guildID)
+	r.cache.Delete(tagsCacheKey(guildID))
+	return nil
+}
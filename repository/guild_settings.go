@@ -10,6 +10,8 @@ import (
 	"github.com/lib/pq"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+
+	"github.com/caquillo07/rotom-bot/metrics"
 )
 
 // GuildSettings contains all the personal settings for a given guild
@@ -27,8 +29,9 @@ type GuildSettings struct {
 	// LastUpdatedBy is the username of the person who updated this config last
 	LastUpdatedBy string
 
-	// BotPrefix is the prefix used to check bot commands for this guild
-	BotPrefix string
+	// BotPrefixes are the prefixes used to check bot commands for this guild,
+	// the first one is the main prefix shown on help messages.
+	BotPrefixes pq.StringArray
 
 	// BotAdminRoles an array of role IDs that can admin the bot without the
 	// guild administrator permission
@@ -42,11 +45,50 @@ type GuildSettings struct {
 	// respond to.
 	ListeningChannels GuildSettingChannels
 
+	// IgnoredChannels is a list of channels the bot will not respond to,
+	// the opposite of ListeningChannels.
+	IgnoredChannels GuildSettingChannels
+
+	// IgnoredUsers is a list of user IDs the bot will not respond to
+	IgnoredUsers pq.StringArray
+
+	// IgnoredRoles is a list of role IDs whose members the bot will not
+	// respond to.
+	IgnoredRoles pq.StringArray
+
+	// DisabledCommands is a list of commands and aliases the bot will ignore
+	// on the guild, or only on some of its channels.
+	DisabledCommands GuildDisabledCommands
+
+	// CommandAliases are the guild's own aliases, on top of the built in
+	// ones, keyed by alias with the name of the command as value.
+	CommandAliases GuildCommandAliases
+
+	// RaidChannelID is the channel Max Raid lobbies are hosted on, any
+	// listening channel can be used if empty.
+	RaidChannelID string
+
+	// AnnouncementsChannelID is the channel the bot's update announcements
+	// are posted on, the guild gets none if empty.
+	AnnouncementsChannelID string
+
+	// Timezone is the IANA name of the guild's timezone, i.e.
+	// America/New_York. Times are shown and parsed in UTC if empty.
+	Timezone string
+
+	// QuietHours is the time range, on the guild's timezone, in which raid
+	// notifications are not sent, i.e. 23:00-07:00. Empty if there is none.
+	QuietHours string
+
 	// CreatedAt the date the user identity was created
 	CreatedAt time.Time
 
 	// UpdatedAt the date the user identity was last updated
 	UpdatedAt time.Time
+
+	// LeftAt the date the bot was removed from the guild, nil while the bot
+	// is still on it.
+	LeftAt *time.Time
 }
 
 type GuildSettingChannel struct {
@@ -81,6 +123,96 @@ func (j GuildSettingChannels) Value() (driver.Value, error) {
 	return rawJSON, nil
 }
 
+// GuildDisabledCommand is a command or alias disabled on a guild. If it has no
+// channels, the command is disabled on every channel.
+type GuildDisabledCommand struct {
+	Name       string   `json:"name"`
+	ChannelIDs []string `json:"channelIds,omitempty"`
+}
+
+type GuildDisabledCommands []*GuildDisabledCommand
+
+// Scan scan value into Jsonb, implements sql.Scanner interface
+func (j *GuildDisabledCommands) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := make([]*GuildDisabledCommand, 0)
+	err := json.Unmarshal(bytes, &result)
+	*j = result
+	return err
+}
+
+// Value return json value, implement driver.Valuer interface
+func (j GuildDisabledCommands) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	rawJSON, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return rawJSON, nil
+}
+
+// Find returns the disabled command with the given name, or nil if the
+// command is not disabled anywhere.
+func (j GuildDisabledCommands) Find(name string) *GuildDisabledCommand {
+	for _, c := range j {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// IsDisabled checks whether the command or alias with the given name is
+// disabled on the channel.
+func (j GuildDisabledCommands) IsDisabled(name, channelID string) bool {
+	c := j.Find(name)
+	if c == nil {
+		return false
+	}
+	if len(c.ChannelIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ChannelIDs {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+type GuildCommandAliases map[string]string
+
+// Scan scan value into Jsonb, implements sql.Scanner interface
+func (j *GuildCommandAliases) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+
+	result := make(map[string]string)
+	err := json.Unmarshal(bytes, &result)
+	*j = result
+	return err
+}
+
+// Value return json value, implement driver.Valuer interface
+func (j GuildCommandAliases) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	rawJSON, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return rawJSON, nil
+}
+
 // CreateGuildSettings creates a new config for a guild, and save a copy in the
 // cache that does not expire.
 func (r *Repository) CreateGuildSettings(config *GuildSettings) error {
@@ -96,8 +228,11 @@ func (r *Repository) CreateGuildSettings(config *GuildSettings) error {
 // cache on miss.
 func (r *Repository) GetGuildSettings(guildID string) (*GuildSettings, error) {
 	if cached, found := r.cache.Get(guildID); found {
+		metrics.GuildSettingsCache.WithLabelValues("hit").Inc()
 		return cached.(*GuildSettings), nil
 	}
+	metrics.GuildSettingsCache.WithLabelValues("miss").Inc()
+
 	var c GuildSettings
 	if err := r.db.Where("guild_id = ?", guildID).Take(&c).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
@@ -122,3 +257,41 @@ func (r *Repository) UpdateGuildSettings(settings *GuildSettings) error {
 	r.cache.Set(settings.DiscordID, settings, cache.NoExpiration)
 	return nil
 }
+
+// EvictGuildSettings removes the guild's settings from the cache, forcing the
+// next lookup to go to the database.
+func (r *Repository) EvictGuildSettings(guildID string) {
+	r.cache.Delete(guildID)
+}
+
+// EvictAllGuildSettings empties the guild settings cache, along with the rest
+// of the cached guild data.
+func (r *Repository) EvictAllGuildSettings() {
+	r.cache.Flush()
+}
+
+// GuildsLeftBefore returns the IDs of the guilds the bot was removed from
+// before the given time.
+func (r *Repository) GuildsLeftBefore(t time.Time) ([]string, error) {
+	var guildIDs []string
+	err := r.db.Model(&GuildSettings{}).
+		Where("left_at IS NOT NULL AND left_at < ?", t).
+		Pluck("guild_id", &guildIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return guildIDs, nil
+}
+
+// GuildsWithAnnouncements returns the settings of the guilds the bot is on
+// that opted in to the update announcements.
+func (r *Repository) GuildsWithAnnouncements() ([]*GuildSettings, error) {
+	settings := make([]*GuildSettings, 0)
+	err := r.db.Where("left_at IS NULL AND announcements_channel_id IS NOT NULL AND announcements_channel_id <> ''").
+		Order("guild_id").
+		Find(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
@@ -155,11 +155,10 @@ func (p *Pokemon) CaptureRate(ball *PokeBall, level, iv int, isGmax, isPromo boo
 // Den will try to find the given Den, if it does not exist it
 // will return a `ErrDenDoesNotExist` error
 func (r *Repository) Den(denNumber string) (*Den, error) {
-	if d, ok := r.dens[denNumber]; ok {
-		// return a copy of the original to not have unwanted changes to map
-		// storage
-		c := *d
-		return &c, nil
+	if d, ok := r.snapshot().dens[denNumber]; ok {
+		// return a copy of the original to not have unwanted changes to the
+		// shared snapshot
+		return d.clone(), nil
 	}
 	return nil, ErrDenDoesNotExist
 }
@@ -167,6 +166,34 @@ func (r *Repository) Den(denNumber string) (*Den, error) {
 // Ball will try to find the given ball, if it does not exist it
 // will return a `ErrBallDoesNotExist` error
 func (r *Repository) Ball(ball string) (*PokeBall, error) {
+	if b, ok := r.snapshot().balls[ballID(ball)]; ok {
+		// return a copy of the original to not have unwanted changes to the
+		// shared snapshot
+		c := *b
+		return &c, nil
+	}
+	return nil, ErrBallDoesNotExist
+}
+
+// BallSuggestions returns up to n balls with the closest names to the given
+// ball name, best match first.
+func (r *Repository) BallSuggestions(ball string, n int) []Match {
+	balls := r.snapshot().balls
+	ids := make([]string, 0, len(balls))
+	for id := range balls {
+		ids = append(ids, id)
+	}
+
+	// the IDs are what the users type in, but we want to show the full name
+	matches := ClosestMatches(ballID(ball), ids, n)
+	for i, m := range matches {
+		matches[i].Name = balls[m.Name].Name
+	}
+	return matches
+}
+
+// ballID returns the ID of the ball the given name refers to.
+func ballID(ball string) string {
 
 	// first clean the input a bit, we will remove all white spaces,
 	// then remove the "ball" part and any hyphens/underscores if present,
@@ -182,14 +209,7 @@ func (r *Repository) Ball(ball string) (*PokeBall, error) {
 	default:
 		// ignore
 	}
-
-	if b, ok := r.balls[ball]; ok {
-		// return a copy of the original to not have unwanted changes to map
-		// storage
-		c := *b
-		return &c, nil
-	}
-	return nil, ErrBallDoesNotExist
+	return ball
 }
 
 // BallsCatchRatesForPokemon returns a list of all poke balls, sorted by
@@ -199,7 +219,7 @@ func (r *Repository) BallsCatchRatesForPokemon(pkm *Pokemon) []*PokeBall {
 	// state. This array is very small, so its ok to do this on every command
 	// call that needs it.
 	balls := make([]*PokeBall, 0)
-	for _, ball := range r.balls {
+	for _, ball := range r.snapshot().balls {
 		// make a copy of the ball, in case we need to modify it
 		newBall := *ball
 		newBall.Modifier = newBall.CatchModifier(pkm)
@@ -216,27 +236,70 @@ func (r *Repository) BallsCatchRatesForPokemon(pkm *Pokemon) []*PokeBall {
 // Pokemon will try to find the given Pokemon, if it does not exist it
 // will return a `ErrBallDoesNotExist` error
 func (r *Repository) Pokemon(name string) (*Pokemon, error) {
-	if p, ok := r.pokemon[strings.ToLower(name)]; ok {
-		// return a copy of the original to not have unwanted changes to map
-		// storage
-		c := *p
-		return &c, nil
+	if p, ok := r.snapshot().pokemon[pokemonKey(name)]; ok {
+		// return a copy of the original to not have unwanted changes to the
+		// shared snapshot
+		return p.clone(), nil
 	}
 	return nil, ErrPokemonDoesNotExist
 }
 
+// regionalSuffixes maps the suffixes the dens use for the regional forms, to
+// the prefix pokemon.json uses for them, i.e. Meowth-Galar is Galarian Meowth.
+var regionalSuffixes = map[string]string{
+	"-galar": "galarian ",
+	"-alola": "alolan ",
+}
+
+// pokemonKey returns the key a Pokémon is looked up by, so the different
+// ways its name is written resolve to the same Pokémon. Regional suffixes are
+// turned into the regional prefix, and the gender symbols into the name
+// pokemon.json uses, i.e. Nidoran♀ is Nidoran Female and Nidoran♂ is Nidoran.
+func pokemonKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.ReplaceAll(key, "♀", " female")
+	key = strings.ReplaceAll(key, "♂", "")
+	for suffix, prefix := range regionalSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			key = prefix + strings.TrimSuffix(key, suffix)
+		}
+	}
+	return strings.Join(strings.Fields(key), " ")
+}
+
+// PokemonSuggestions returns up to n Pokemon with the closest names to the
+// given name, best match first.
+func (r *Repository) PokemonSuggestions(name string, n int) []Match {
+	pokemon := r.snapshot().pokemon
+	names := make([]string, 0, len(pokemon))
+	for _, p := range pokemon {
+		names = append(names, p.Name)
+	}
+	return ClosestMatches(name, names, n)
+}
+
 // PokemonType will try to find the a Pokemon type, if it does not exist it
 // will return a `ErrTypeDoesNotExist` error
 func (r *Repository) PokemonType(name string) (*PokemonType, error) {
-	if t, ok := r.types[strings.ToLower(name)]; ok {
-		// return a copy of the original to not have unwanted changes to map
-		// storage
-		c := *t
-		return &c, nil
+	if t, ok := r.snapshot().types[strings.ToLower(name)]; ok {
+		// return a copy of the original to not have unwanted changes to the
+		// shared snapshot
+		return t.clone(), nil
 	}
 	return nil, ErrTypeDoesNotExist
 }
 
+// PokemonTypeSuggestions returns up to n types with the closest names to the
+// given name, best match first.
+func (r *Repository) PokemonTypeSuggestions(name string, n int) []Match {
+	types := r.snapshot().types
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	return ClosestMatches(name, names, n)
+}
+
 // CatchModifier returns the actual modifier for the given Pokemon
 func (b *PokeBall) CatchModifier(pkm *Pokemon) float64 {
 	mod := b.Modifier
@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestPokemonKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Pikachu", "pikachu"},
+		{"  Mr Mime ", "mr mime"},
+		{"Galarian Meowth", "galarian meowth"},
+		{"Meowth-Galar", "galarian meowth"},
+		{"Mr Mime-Galar", "galarian mr mime"},
+		{"Vulpix-Alola", "alolan vulpix"},
+		{"Nidoran♀", "nidoran female"},
+		{"Nidoran♂", "nidoran"},
+		{"Nidoran Female", "nidoran female"},
+		{"Porygon-Z", "porygon-z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pokemonKey(tt.name); got != tt.want {
+				t.Errorf("pokemonKey(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
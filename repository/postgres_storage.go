@@ -2,10 +2,9 @@ package repository
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -39,12 +38,12 @@ var (
 // Repository handles all the searching and saving of all of storage related
 // data in the app.
 type Repository struct {
-	db      *gorm.DB
-	cache   *cache.Cache
-	dens    map[string]*Den
-	balls   map[string]*PokeBall
-	pokemon map[string]*Pokemon
-	types   map[string]*PokemonType
+	db    *gorm.DB
+	cache *cache.Cache
+
+	// data holds the *dataSnapshot currently in use, it is swapped as a whole
+	// when the data is reloaded.
+	data atomic.Value
 }
 
 // NewRepository creates a new instance of the repository
@@ -54,63 +53,17 @@ type Repository struct {
 // TODO: This storage repo is a hybrid of JSON + Postgres for now till
 //  everything is migrated over
 func NewRepository(db *gorm.DB) (*Repository, error) {
-	// load all the json files, starting by dens
-	dens := make([]*Den, 0)
-	if err := loadJSONInto("data/dens.json", &dens); err != nil {
-		return nil, fmt.Errorf("failed to load dens.json: %+v", err)
-	}
-
-	// build the dens map for quick lookups
-	densMap := make(map[string]*Den)
-	for _, den := range dens {
-		// if the file is structured properly, this should just work...
-		// todo(hector) - add validation here to prevent panics or overwrites?
-		densMap[den.Number] = den
-	}
-
-	balls := make([]*PokeBall, 0)
-	if err := loadJSONInto("data/balls.json", &balls); err != nil {
-		return nil, fmt.Errorf("failed to load balls.json: %+v", err)
-	}
-
-	ballsMap := make(map[string]*PokeBall)
-	for _, ball := range balls {
-		// all names are "<something> Ball", so just remove the " Ball" part
-		lowered := strings.ToLower(ball.Name)
-		ball.ID = strings.ReplaceAll(lowered, " ball", "")
-		ballsMap[ball.ID] = ball
-	}
-
-	pokemons := make([]*Pokemon, 0)
-	if err := loadJSONInto("data/pokemon.json", &pokemons); err != nil {
-		return nil, fmt.Errorf("failed to load pokemon.json: %+v", err)
-	}
-
-	pkmMap := make(map[string]*Pokemon)
-	for _, pkm := range pokemons {
-		lowered := strings.ToLower(pkm.Name)
-		pkmMap[lowered] = pkm
-	}
-
-	types := make([]*PokemonType, 0)
-	if err := loadJSONInto("data/types.json", &types); err != nil {
-		return nil, fmt.Errorf("failed to load types.json: %+v", err)
+	snapshot, err := loadSnapshot()
+	if err != nil {
+		return nil, err
 	}
 
-	typesMap := make(map[string]*PokemonType)
-	for _, pkmType := range types {
-		lowered := strings.ToLower(pkmType.Name)
-		typesMap[lowered] = pkmType
+	r := &Repository{
+		db:    db,
+		cache: cache.New(5*time.Minute, 10*time.Minute),
 	}
-
-	return &Repository{
-		dens:    densMap,
-		balls:   ballsMap,
-		pokemon: pkmMap,
-		types:   typesMap,
-		db:      db,
-		cache:   cache.New(5*time.Minute, 10*time.Minute),
-	}, nil
+	r.data.Store(snapshot)
+	return r, nil
 }
 
 func loadJSONInto(fileLocation string, i interface{}) error {
@@ -126,3 +79,11 @@ func loadJSONInto(fileLocation string, i interface{}) error {
 
 	return json.Unmarshal(densBuf, &i)
 }
+
+// Ping checks the connection to the database is still alive.
+func (r *Repository) Ping() error {
+	if r.db == nil {
+		return errors.New("no database connection")
+	}
+	return r.db.DB().Ping()
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// RaidLobby is a Max Raid hosted on a guild, challengers join it by reacting
+// to its message.
+type RaidLobby struct {
+
+	// ID internal unique ID
+	ID int `json:"-"`
+
+	// GuildID and ChannelID are where the lobby was hosted
+	GuildID   string `json:"guildId"`
+	ChannelID string `json:"channelId"`
+
+	// MessageID is the ID of the lobby message challengers react to
+	MessageID string `json:"messageId"`
+
+	// HostID is the ID of the user hosting the raid
+	HostID string `json:"hostId"`
+
+	// Den is the number of the den being hosted, empty when the raid is for
+	// a specific Pokémon.
+	Den string `json:"den"`
+
+	// Pokemon is the name of the Pokémon being hosted, empty when the raid
+	// is for a den.
+	Pokemon string `json:"pokemon"`
+
+	// Code is the link code, only sent to the accepted challengers
+	Code string `json:"-"`
+
+	// Gigantamax and Shiny describe the Pokémon being hosted
+	Gigantamax bool `json:"gigantamax"`
+	Shiny      bool `json:"shiny"`
+
+	// Challengers are the IDs of the users that joined the raid, and Queue
+	// the ones waiting for a free spot.
+	Challengers pq.StringArray `json:"challengers"`
+	Queue       pq.StringArray `json:"queue"`
+
+	// ExpiresAt is when the lobby is closed if the host does not close it
+	// first.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// ClosedAt the date the lobby was closed, nil while it is open
+	ClosedAt *time.Time `json:"closedAt"`
+
+	// CreatedAt the date the lobby was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date the lobby was last updated
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateRaidLobby saves a new lobby. Hosts can only have one open lobby on
+// each guild, ErrRecordExists is returned if they already have one.
+func (r *Repository) CreateRaidLobby(lobby *RaidLobby) error {
+	if err := r.db.Create(lobby).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrRecordExists
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateRaidLobby saves the changes made to the lobby's challengers, queue
+// and closing date. Lobbies that no longer exist are not created again.
+func (r *Repository) UpdateRaidLobby(lobby *RaidLobby) error {
+	result := r.db.Model(lobby).Updates(map[string]interface{}{
+		"challengers": lobby.Challengers,
+		"queue":       lobby.Queue,
+		"closed_at":   lobby.ClosedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetRaidLobby returns the lobby posted on the given message.
+func (r *Repository) GetRaidLobby(messageID string) (*RaidLobby, error) {
+	var lobby RaidLobby
+	if err := r.db.Where("message_id = ?", messageID).Take(&lobby).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &lobby, nil
+}
+
+// GetOpenRaidLobbyByHost returns the lobby the user is hosting on the guild.
+func (r *Repository) GetOpenRaidLobbyByHost(guildID, hostID string) (*RaidLobby, error) {
+	var lobby RaidLobby
+	err := r.db.Where("guild_id = ? AND host_id = ? AND closed_at IS NULL", guildID, hostID).
+		Take(&lobby).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &lobby, nil
+}
+
+// OpenRaidLobbies returns all the lobbies that have not been closed yet.
+func (r *Repository) OpenRaidLobbies() ([]*RaidLobby, error) {
+	lobbies := make([]*RaidLobby, 0)
+	if err := r.db.Where("closed_at IS NULL").Find(&lobbies).Error; err != nil {
+		return nil, err
+	}
+	return lobbies, nil
+}
+
+// RaidLobbiesExpiredBy returns the open lobbies that expire before the given
+// time.
+func (r *Repository) RaidLobbiesExpiredBy(t time.Time) ([]*RaidLobby, error) {
+	lobbies := make([]*RaidLobby, 0)
+	if err := r.db.Where("closed_at IS NULL AND expires_at < ?", t).Find(&lobbies).Error; err != nil {
+		return nil, err
+	}
+	return lobbies, nil
+}
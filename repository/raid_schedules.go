@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// RaidSchedule is a raid announced ahead of time, users RSVP to it by
+// reacting to its message and get pinged before it starts.
+type RaidSchedule struct {
+
+	// ID internal unique ID, also used to refer to the raid on commands
+	ID int `json:"id"`
+
+	// GuildID and ChannelID are where the raid was announced
+	GuildID   string `json:"guildId"`
+	ChannelID string `json:"channelId"`
+
+	// MessageID is the ID of the announcement users react to
+	MessageID string `json:"messageId"`
+
+	// HostID is the ID of the user hosting the raid
+	HostID string `json:"hostId"`
+
+	// Title describes the raid, i.e. "Gmax Toxtricity"
+	Title string `json:"title"`
+
+	// StartsAt is when the raid starts
+	StartsAt time.Time `json:"startsAt"`
+
+	// RemindMinutes is how many minutes before the start the users that
+	// RSVP'd are pinged.
+	RemindMinutes int `json:"remindMinutes"`
+
+	// RSVPs are the IDs of the users that will join the raid
+	RSVPs pq.StringArray `gorm:"column:rsvps" json:"rsvps"`
+
+	// RemindedAt the date the reminder was sent, nil until then
+	RemindedAt *time.Time `json:"remindedAt"`
+
+	// CancelledAt the date the raid was cancelled, nil unless it was
+	CancelledAt *time.Time `json:"cancelledAt"`
+
+	// CreatedAt the date the raid was scheduled
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date the raid was last updated
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateRaidSchedule saves a new scheduled raid.
+func (r *Repository) CreateRaidSchedule(schedule *RaidSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// UpdateRaidSchedule saves the changes made to the scheduled raid's RSVPs,
+// reminder and cancellation. Raids that no longer exist are not created again.
+func (r *Repository) UpdateRaidSchedule(schedule *RaidSchedule) error {
+	result := r.db.Model(schedule).Updates(map[string]interface{}{
+		"rsvps":        schedule.RSVPs,
+		"reminded_at":  schedule.RemindedAt,
+		"cancelled_at": schedule.CancelledAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetRaidSchedule returns the guild's scheduled raid with the given ID.
+func (r *Repository) GetRaidSchedule(guildID string, id int) (*RaidSchedule, error) {
+	return r.takeRaidSchedule(r.db.Where("guild_id = ? AND id = ?", guildID, id))
+}
+
+// GetRaidScheduleByMessage returns the scheduled raid announced on the given
+// message.
+func (r *Repository) GetRaidScheduleByMessage(messageID string) (*RaidSchedule, error) {
+	return r.takeRaidSchedule(r.db.Where("message_id = ?", messageID))
+}
+
+func (r *Repository) takeRaidSchedule(query *gorm.DB) (*RaidSchedule, error) {
+	var schedule RaidSchedule
+	if err := query.Take(&schedule).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// UpcomingRaidSchedules returns the raids that have not started or been
+// cancelled yet, soonest first. An empty guild ID returns the ones of every
+// guild.
+func (r *Repository) UpcomingRaidSchedules(guildID string) ([]*RaidSchedule, error) {
+	query := r.db.Where("cancelled_at IS NULL AND starts_at > ?", time.Now())
+	if guildID != "" {
+		query = query.Where("guild_id = ?", guildID)
+	}
+
+	schedules := make([]*RaidSchedule, 0)
+	if err := query.Order("starts_at").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// RaidSchedulesDueForReminder returns the raids that have not started yet,
+// and whose reminder should have been sent by the given time.
+func (r *Repository) RaidSchedulesDueForReminder(t time.Time) ([]*RaidSchedule, error) {
+	schedules := make([]*RaidSchedule, 0)
+	err := r.db.
+		Where("cancelled_at IS NULL AND reminded_at IS NULL AND starts_at > ?", t).
+		Where("starts_at - remind_minutes * interval '1 minute' <= ?", t).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+)
+
+// RaidSubscription is a Pokémon a user wants to be pinged about when someone
+// hosts a raid for it on the guild.
+type RaidSubscription struct {
+
+	// ID internal unique ID
+	ID int `json:"-"`
+
+	// GuildID is the discord ID of the guild the subscription belongs to
+	GuildID string `json:"guildId"`
+
+	// UserID is the discord ID of the subscribed user
+	UserID string `json:"userId"`
+
+	// Pokemon is the name of the Pokémon the user is hunting
+	Pokemon string `json:"pokemon"`
+
+	// Shiny and Gigantamax limit the subscription to shiny or Gigantamax
+	// raids, otherwise any raid for the Pokémon matches.
+	Shiny      bool `json:"shiny"`
+	Gigantamax bool `json:"gigantamax"`
+
+	// CreatedAt the date the subscription was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date the subscription was last updated
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GetRaidSubscriptions returns the user's subscriptions on the guild.
+func (r *Repository) GetRaidSubscriptions(guildID, userID string) ([]*RaidSubscription, error) {
+	subscriptions := make([]*RaidSubscription, 0)
+	err := r.db.Where("guild_id = ? AND user_id = ?", guildID, userID).
+		Order("pokemon").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// CreateRaidSubscription saves a new subscription.
+func (r *Repository) CreateRaidSubscription(subscription *RaidSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// DeleteRaidSubscription removes the user's subscription to the Pokémon.
+func (r *Repository) DeleteRaidSubscription(subscription *RaidSubscription) error {
+	result := r.db.
+		Where("guild_id = ? AND user_id = ? AND pokemon = ?", subscription.GuildID, subscription.UserID, subscription.Pokemon).
+		Where("shiny = ? AND gigantamax = ?", subscription.Shiny, subscription.Gigantamax).
+		Delete(&RaidSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// RaidSubscribers returns the IDs of the users on the guild subscribed to
+// raids for the Pokémon, with the given shininess and Gigantamax form.
+func (r *Repository) RaidSubscribers(guildID, pokemon string, shiny, gigantamax bool) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&RaidSubscription{}).
+		Where("guild_id = ? AND pokemon = ?", guildID, pokemon).
+		Where("(shiny = false OR shiny = ?) AND (gigantamax = false OR gigantamax = ?)", shiny, gigantamax).
+		Order("user_id").
+		Pluck("DISTINCT user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	densFile    = "data/dens.json"
+	ballsFile   = "data/balls.json"
+	pokemonFile = "data/pokemon.json"
+	typesFile   = "data/types.json"
+)
+
+// dataSnapshot holds all the JSON backed data at a given point in time. A
+// snapshot must never be modified once it is created, reloading the data
+// creates a brand new snapshot that replaces the old one as a whole.
+type dataSnapshot struct {
+	dens     map[string]*Den
+	balls    map[string]*PokeBall
+	pokemon  map[string]*Pokemon
+	types    map[string]*PokemonType
+	loadedAt time.Time
+}
+
+// loadSnapshot loads up the json files inside the /data folder at the
+// project's root, and creates maps for quick look up. The snapshot is
+// validated before it is returned.
+func loadSnapshot() (*dataSnapshot, error) {
+	// load all the json files, starting by dens
+	dens := make([]*Den, 0)
+	if err := loadJSONInto(densFile, &dens); err != nil {
+		return nil, fmt.Errorf("failed to load dens.json: %+v", err)
+	}
+
+	balls := make([]*PokeBall, 0)
+	if err := loadJSONInto(ballsFile, &balls); err != nil {
+		return nil, fmt.Errorf("failed to load balls.json: %+v", err)
+	}
+
+	pokemons := make([]*Pokemon, 0)
+	if err := loadJSONInto(pokemonFile, &pokemons); err != nil {
+		return nil, fmt.Errorf("failed to load pokemon.json: %+v", err)
+	}
+
+	types := make([]*PokemonType, 0)
+	if err := loadJSONInto(typesFile, &types); err != nil {
+		return nil, fmt.Errorf("failed to load types.json: %+v", err)
+	}
+
+	snapshot := &dataSnapshot{
+		dens:     make(map[string]*Den),
+		balls:    make(map[string]*PokeBall),
+		pokemon:  make(map[string]*Pokemon),
+		types:    make(map[string]*PokemonType),
+		loadedAt: time.Now(),
+	}
+
+	// build the maps for quick lookups, making sure nothing gets overwritten
+	// along the way.
+	var problems []string
+	for _, den := range dens {
+		if den.Number == "" {
+			problems = append(problems, "dens.json: den without a number")
+			continue
+		}
+		if _, ok := snapshot.dens[den.Number]; ok {
+			problems = append(problems, fmt.Sprintf("dens.json: duplicated den %s", den.Number))
+		}
+		snapshot.dens[den.Number] = den
+	}
+
+	for _, ball := range balls {
+		// all names are "<something> Ball", so just remove the " Ball" part
+		lowered := strings.ToLower(ball.Name)
+		ball.ID = strings.ReplaceAll(lowered, " ball", "")
+		if ball.ID == "" {
+			problems = append(problems, "balls.json: ball without a name")
+			continue
+		}
+		if _, ok := snapshot.balls[ball.ID]; ok {
+			problems = append(problems, fmt.Sprintf("balls.json: duplicated ball %s", ball.Name))
+		}
+		snapshot.balls[ball.ID] = ball
+	}
+
+	for _, pkm := range pokemons {
+		key := pokemonKey(pkm.Name)
+		if key == "" {
+			problems = append(problems, fmt.Sprintf("pokemon.json: pokemon #%d without a name", pkm.DexID))
+			continue
+		}
+		if _, ok := snapshot.pokemon[key]; ok {
+			problems = append(problems, fmt.Sprintf("pokemon.json: duplicated pokemon %s", pkm.Name))
+		}
+		snapshot.pokemon[key] = pkm
+	}
+
+	for _, pkmType := range types {
+		lowered := strings.ToLower(pkmType.Name)
+		if lowered == "" {
+			problems = append(problems, "types.json: type without a name")
+			continue
+		}
+		if _, ok := snapshot.types[lowered]; ok {
+			problems = append(problems, fmt.Sprintf("types.json: duplicated type %s", pkmType.Name))
+		}
+		snapshot.types[lowered] = pkmType
+	}
+
+	if len(snapshot.dens) == 0 || len(snapshot.balls) == 0 ||
+		len(snapshot.pokemon) == 0 || len(snapshot.types) == 0 {
+		problems = append(problems, "one or more data files are empty")
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Errorf("invalid data: %s", strings.Join(problems, "; "))
+	}
+	return snapshot, nil
+}
+
+// snapshot returns the data snapshot currently in use.
+func (r *Repository) snapshot() *dataSnapshot {
+	return r.data.Load().(*dataSnapshot)
+}
+
+// ReloadData loads and validates the data files again, and swaps the data
+// in use with it. Only the problems loadSnapshot rejects, like duplicated or
+// empty records, stop the reload and keep the data currently in use. The
+// cross-checks done by ValidateData are not enforced here, those are meant to
+// be run with the data validate command before the files are changed.
+func (r *Repository) ReloadData() error {
+	snapshot, err := loadSnapshot()
+	if err != nil {
+		return err
+	}
+	r.data.Store(snapshot)
+	return nil
+}
+
+// DataLoadedAt returns when the data currently in use was loaded.
+func (r *Repository) DataLoadedAt() time.Time {
+	return r.snapshot().loadedAt
+}
+
+// The following clone methods make deep copies of the data, so callers can
+// never modify the shared snapshot through the values handed out.
+
+func (d *Den) clone() *Den {
+	c := *d
+	c.Sword = cloneDenPokemon(d.Sword)
+	c.Shield = cloneDenPokemon(d.Shield)
+	return &c
+}
+
+func cloneDenPokemon(pokemon []*DenPokemon) []*DenPokemon {
+	if pokemon == nil {
+		return nil
+	}
+	c := make([]*DenPokemon, len(pokemon))
+	for i, p := range pokemon {
+		cp := *p
+		c[i] = &cp
+	}
+	return c
+}
+
+func (p *Pokemon) clone() *Pokemon {
+	c := *p
+	c.Dens.Shield = cloneStrings(p.Dens.Shield)
+	c.Dens.Sword = cloneStrings(p.Dens.Sword)
+	c.Evolutions = cloneStrings(p.Evolutions)
+	c.Forms = cloneStrings(p.Forms)
+	return &c
+}
+
+func (t *PokemonType) clone() *PokemonType {
+	c := *t
+	c.Offensive = cloneEffectiveness(t.Offensive)
+	c.Defensive = cloneEffectiveness(t.Defensive)
+	return &c
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(s)), s...)
+}
+
+func cloneEffectiveness(m map[string]float64) map[string]float64 {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]float64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
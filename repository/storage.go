@@ -1,13 +1,30 @@
 package repository
 
-import "github.com/pkg/errors"
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
 
 var (
 	// ErrRecordNotFound is a generic error returned when a given record is not
 	// found
 	ErrRecordNotFound = errors.New("record not found")
+
+	// ErrRecordExists is a generic error returned when a record can not be
+	// created because it conflicts with one that already exists
+	ErrRecordExists = errors.New("record already exists")
 )
 
+// uniqueViolation is the PostgreSQL error code for unique constraint
+// violations.
+const uniqueViolation = "23505"
+
+// isUniqueViolation checks whether the error is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	return ok && pqErr.Code == uniqueViolation
+}
+
 // Storage will define the required methods required by the storage manager
 // inside this bot. This is an interface to allow for future implementations
 // of non-sql based storages, since someone self hosting may not have PostgreSQL
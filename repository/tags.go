@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Tag is a custom command created by a guild, it replies with the stored
+// content.
+type Tag struct {
+
+	// ID internal unique ID
+	ID int `json:"-"`
+
+	// GuildID is the discord ID of the guild the tag belongs to
+	GuildID string `json:"guildId"`
+
+	// Name is what the tag is invoked with
+	Name string `json:"name"`
+
+	// Content is what the bot replies with
+	Content string `json:"content"`
+
+	// Embed sends the content inside of an embed instead of as plain text
+	Embed bool `json:"embed"`
+
+	// CreatedBy is the ID of the user who created the tag
+	CreatedBy string `json:"createdBy"`
+
+	// CreatedAt the date the tag was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date the tag was last updated
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func tagsCacheKey(guildID string) string {
+	return "tags:" + guildID
+}
+
+// GetTags returns all the tags of the given guild. Like the guild settings,
+// this method will first check cache to prevent a DB call, and will hydrate
+// cache on miss.
+func (r *Repository) GetTags(guildID string) ([]*Tag, error) {
+	if cached, found := r.cache.Get(tagsCacheKey(guildID)); found {
+		return cached.([]*Tag), nil
+	}
+
+	tags := make([]*Tag, 0)
+	if err := r.db.Where("guild_id = ?", guildID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(tagsCacheKey(guildID), tags, cache.NoExpiration)
+	return tags, nil
+}
+
+// GetTag returns the guild's tag with the given name.
+func (r *Repository) GetTag(guildID, name string) (*Tag, error) {
+	tags, err := r.GetTags(guildID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
+
+// CreateTag saves a new tag for the guild.
+func (r *Repository) CreateTag(tag *Tag) error {
+	if err := r.db.Create(tag).Error; err != nil {
+		return err
+	}
+	r.cache.Delete(tagsCacheKey(tag.GuildID))
+	return nil
+}
+
+// UpdateTag saves the changes made to the tag.
+func (r *Repository) UpdateTag(tag *Tag) error {
+	if err := r.db.Save(tag).Error; err != nil {
+		return err
+	}
+	r.cache.Delete(tagsCacheKey(tag.GuildID))
+	return nil
+}
+
+// DeleteTag removes the guild's tag with the given name.
+func (r *Repository) DeleteTag(guildID, name string) error {
+	result := r.db.Where("guild_id = ? AND name = ?", guildID, name).Delete(&Tag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	r.cache.Delete(tagsCacheKey(guildID))
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
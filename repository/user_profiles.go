@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// UserProfile holds the trainer information a user shares with others, like
+// their friend code and in game name.
+type UserProfile struct {
+
+	// ID internal unique ID
+	ID int `json:"-"`
+
+	// UserID is the discord ID of the user the profile belongs to
+	UserID string `json:"userId"`
+
+	// FriendCode is the user's Switch friend code, i.e. SW-1234-5678-9012
+	FriendCode string `json:"friendCode"`
+
+	// IGN is the user's in game name
+	IGN string `gorm:"column:ign" json:"ign"`
+
+	// Game is the game the user plays, either sword or shield
+	Game string `json:"game"`
+
+	// Global makes the profile visible on every guild, otherwise it is only
+	// visible on the guilds listed on GuildIDs.
+	Global bool `json:"global"`
+
+	// GuildIDs are the guilds the user shared the profile with
+	GuildIDs pq.StringArray `json:"guildIds"`
+
+	// CreatedAt the date the profile was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt the date the profile was last updated
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// VisibleOn checks whether the profile can be looked up from the given
+// guild, DMs only see global profiles.
+func (p *UserProfile) VisibleOn(guildID string) bool {
+	if p.Global {
+		return true
+	}
+	if guildID == "" {
+		return false
+	}
+	for _, id := range p.GuildIDs {
+		if id == guildID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserProfile returns the profile of the given user.
+func (r *Repository) GetUserProfile(userID string) (*UserProfile, error) {
+	var p UserProfile
+	if err := r.db.Where("user_id = ?", userID).Take(&p).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveUserProfile creates the profile, or saves the changes made to it.
+func (r *Repository) SaveUserProfile(profile *UserProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// DeleteUserProfile removes the profile of the given user.
+func (r *Repository) DeleteUserProfile(userID string) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&UserProfile{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Abilities a Pokémon can have inside of a den.
+const (
+	DenAbilityStandard       = "Standard"
+	DenAbilityHidden         = "Hidden Ability"
+	DenAbilityHiddenPossible = "Hidden Possible"
+)
+
+var (
+	denAbilities = []string{DenAbilityStandard, DenAbilityHidden, DenAbilityHiddenPossible}
+
+	denKeys        = []string{"den", "sword", "shield"}
+	denPokemonKeys = []string{"name", "ability", "gigantamax"}
+)
+
+// DataProblem is an issue found on the data files while validating them.
+type DataProblem struct {
+	// File is the data file the problem was found on
+	File string
+
+	// Record identifies the record inside of the file with the problem
+	Record string
+
+	// Problem describes what is wrong with the record
+	Problem string
+
+	// Warning is set for problems that do not break the bot, but should be
+	// cleaned up regardless.
+	Warning bool
+}
+
+func (p DataProblem) String() string {
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("[%s] %s: %s: %s", level, p.File, p.Record, p.Problem)
+}
+
+// ValidateData cross-checks all the data files against each other, and
+// returns every problem found on them. An error is only returned if the files
+// could not be read at all.
+func ValidateData() ([]DataProblem, error) {
+	rawDens := make([]map[string]interface{}, 0)
+	if err := loadJSONInto(densFile, &rawDens); err != nil {
+		return nil, fmt.Errorf("failed to load dens.json: %+v", err)
+	}
+	dens := make([]*Den, 0)
+	if err := loadJSONInto(densFile, &dens); err != nil {
+		return nil, fmt.Errorf("failed to load dens.json: %+v", err)
+	}
+
+	rawPokemon := make([]map[string]interface{}, 0)
+	if err := loadJSONInto(pokemonFile, &rawPokemon); err != nil {
+		return nil, fmt.Errorf("failed to load pokemon.json: %+v", err)
+	}
+	pokemon := make([]*Pokemon, 0)
+	if err := loadJSONInto(pokemonFile, &pokemon); err != nil {
+		return nil, fmt.Errorf("failed to load pokemon.json: %+v", err)
+	}
+
+	rawBalls := make([]map[string]interface{}, 0)
+	if err := loadJSONInto(ballsFile, &rawBalls); err != nil {
+		return nil, fmt.Errorf("failed to load balls.json: %+v", err)
+	}
+
+	types := make([]*PokemonType, 0)
+	if err := loadJSONInto(typesFile, &types); err != nil {
+		return nil, fmt.Errorf("failed to load types.json: %+v", err)
+	}
+
+	v := &dataValidator{
+		dens:    make(map[string]bool),
+		pokemon: make(map[string]bool),
+		types:   make(map[string]bool),
+	}
+	for _, d := range dens {
+		v.dens[d.Number] = true
+	}
+	for _, p := range pokemon {
+		v.pokemon[pokemonKey(p.Name)] = true
+	}
+	for _, t := range types {
+		v.types[t.Name] = true
+	}
+
+	v.validateDens(rawDens, dens)
+	v.validatePokemon(rawPokemon, pokemon)
+	v.validateBalls(rawBalls)
+	v.validateTypes(types)
+	return v.problems, nil
+}
+
+type dataValidator struct {
+	dens     map[string]bool
+	pokemon  map[string]bool
+	types    map[string]bool
+	problems []DataProblem
+}
+
+func (v *dataValidator) addProblem(file, record, problem string, warning bool) {
+	v.problems = append(v.problems, DataProblem{
+		File:    file,
+		Record:  record,
+		Problem: problem,
+		Warning: warning,
+	})
+}
+
+// validateDens makes sure every den Pokémon exists and has a known ability,
+// and that the records do not have any unknown keys.
+func (v *dataValidator) validateDens(raw []map[string]interface{}, dens []*Den) {
+	for i, den := range dens {
+		record := "den " + den.Number
+		for _, key := range unknownKeys(raw[i], denKeys) {
+			v.addProblem(densFile, record, fmt.Sprintf("unknown key %q", key), false)
+		}
+
+		for _, game := range []string{"sword", "shield"} {
+			rawGame, _ := raw[i][game].([]interface{})
+			gamePokemon := den.Sword
+			if game == "shield" {
+				gamePokemon = den.Shield
+			}
+
+			for j, p := range gamePokemon {
+				pkmRecord := fmt.Sprintf("%s (%s) %s", record, game, p.Name)
+				if j < len(rawGame) {
+					rawPkm, _ := rawGame[j].(map[string]interface{})
+					for _, key := range unknownKeys(rawPkm, denPokemonKeys) {
+						v.addProblem(densFile, pkmRecord, fmt.Sprintf("unknown key %q", key), false)
+					}
+				}
+
+				// the dens write the regional forms and genders differently
+				// than pokemon.json, so the names are compared the same way
+				// they are looked up.
+				if !v.pokemon[pokemonKey(p.Name)] {
+					v.addProblem(densFile, pkmRecord, "pokemon does not exist in pokemon.json", false)
+				}
+				if !contains(denAbilities, p.Ability) {
+					v.addProblem(
+						densFile,
+						pkmRecord,
+						fmt.Sprintf("unknown ability %q, must be one of %s", p.Ability, strings.Join(denAbilities, ", ")),
+						false,
+					)
+				}
+			}
+		}
+	}
+}
+
+// validatePokemon makes sure every den a Pokémon is on exists, its types are
+// valid, and it has no null abilities.
+func (v *dataValidator) validatePokemon(raw []map[string]interface{}, pokemon []*Pokemon) {
+	for i, p := range pokemon {
+		record := fmt.Sprintf("#%d %s", p.DexID, p.Name)
+		for _, d := range p.Dens.Sword {
+			if !v.dens[d] {
+				v.addProblem(pokemonFile, record, fmt.Sprintf("sword den %s does not exist in dens.json", d), false)
+			}
+		}
+		for _, d := range p.Dens.Shield {
+			if !v.dens[d] {
+				v.addProblem(pokemonFile, record, fmt.Sprintf("shield den %s does not exist in dens.json", d), false)
+			}
+		}
+
+		if !v.types[p.Type1] {
+			v.addProblem(pokemonFile, record, fmt.Sprintf("type1 %q does not exist in types.json", p.Type1), false)
+		}
+		if p.Type2 != "" && !v.types[p.Type2] {
+			v.addProblem(pokemonFile, record, fmt.Sprintf("type2 %q does not exist in types.json", p.Type2), false)
+		}
+
+		if p.Abilities.Ability1 == "" {
+			v.addProblem(pokemonFile, record, "ability1 is required", false)
+		}
+		abilities, _ := raw[i]["abilities"].(map[string]interface{})
+		if value, ok := abilities["ability2"]; ok && value == nil {
+			v.addProblem(pokemonFile, record, "ability2 is null, use an empty string instead", true)
+		}
+	}
+}
+
+// validateBalls makes sure every ball has a modifier.
+func (v *dataValidator) validateBalls(raw []map[string]interface{}) {
+	for _, ball := range raw {
+		name, _ := ball["name"].(string)
+		modifier, ok := ball["modifier"].(float64)
+		if !ok {
+			v.addProblem(ballsFile, name, "modifier is missing", false)
+			continue
+		}
+		if modifier <= 0 {
+			v.addProblem(ballsFile, name, fmt.Sprintf("modifier must be greater than 0, got %v", modifier), false)
+		}
+	}
+}
+
+// validateTypes makes sure the type effectiveness only references valid
+// types.
+func (v *dataValidator) validateTypes(types []*PokemonType) {
+	for _, t := range types {
+		for _, chart := range []struct {
+			name          string
+			effectiveness map[string]float64
+		}{
+			{"offensive", t.Offensive},
+			{"defensive", t.Defensive},
+		} {
+			for _, name := range sortedKeys(chart.effectiveness) {
+				if !v.types[name] {
+					v.addProblem(
+						typesFile,
+						t.Name,
+						fmt.Sprintf("%s type %q does not exist", chart.name, name),
+						false,
+					)
+				}
+			}
+		}
+	}
+}
+
+// unknownKeys returns the keys in the record that are not on the list of known
+// keys, sorted so the problems are always reported in the same order.
+func unknownKeys(record map[string]interface{}, known []string) []string {
+	unknown := make([]string, 0)
+	for key := range record {
+		if !contains(known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
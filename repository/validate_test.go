@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// decodeRecords decodes the JSON into both the raw records and the typed
+// ones, the same way ValidateData loads the files.
+func decodeRecords(t *testing.T, data string, typed interface{}) []map[string]interface{} {
+	t.Helper()
+	raw := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		t.Fatalf("failed to decode raw records: %v", err)
+	}
+	if typed != nil {
+		if err := json.Unmarshal([]byte(data), typed); err != nil {
+			t.Fatalf("failed to decode records: %v", err)
+		}
+	}
+	return raw
+}
+
+func newTestValidator() *dataValidator {
+	v := &dataValidator{
+		dens:    map[string]bool{"1": true},
+		pokemon: make(map[string]bool),
+		types:   map[string]bool{"Electric": true, "Normal": true},
+	}
+	for _, name := range []string{"Pikachu", "Eevee", "Galarian Darmanitan", "Alolan Vulpix", "Nidoran", "Nidoran Female"} {
+		v.pokemon[pokemonKey(name)] = true
+	}
+	return v
+}
+
+func problemStrings(problems []DataProblem) []string {
+	s := make([]string, len(problems))
+	for i, p := range problems {
+		s[i] = p.String()
+	}
+	return s
+}
+
+func TestValidateDens(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: `[{"den": "1", "sword": [{"name": "Pikachu", "ability": "Standard", "gigantamax": false}], "shield": []}]`,
+			want: []string{},
+		},
+		{
+			name: "unknown den key",
+			data: `[{"den": "1", "sword": [], "shield": [], "crown": []}]`,
+			want: []string{`[error] data/dens.json: den 1: unknown key "crown"`},
+		},
+		{
+			name: "unknown pokemon key",
+			data: `[{"den": "1", "sword": [{"name": "Eevee", "abilty": "Standard", "ability": "Standard"}]}]`,
+			want: []string{`[error] data/dens.json: den 1 (sword) Eevee: unknown key "abilty"`},
+		},
+		{
+			name: "regional suffixes",
+			data: `[{"den": "1", "sword": [{"name": "Darmanitan-Galar", "ability": "Standard"}],
+				"shield": [{"name": "Vulpix-Alola", "ability": "Standard"}]}]`,
+			want: []string{},
+		},
+		{
+			name: "gender symbols",
+			data: `[{"den": "1", "sword": [{"name": "Nidoran♀", "ability": "Standard"}],
+				"shield": [{"name": "Nidoran♂", "ability": "Hidden Possible"}]}]`,
+			want: []string{},
+		},
+		{
+			name: "regional form that does not exist",
+			data: `[{"den": "1", "sword": [{"name": "Pikachu-Galar", "ability": "Standard"}]}]`,
+			want: []string{`[error] data/dens.json: den 1 (sword) Pikachu-Galar: pokemon does not exist in pokemon.json`},
+		},
+		{
+			name: "unknown pokemon",
+			data: `[{"den": "1", "shield": [{"name": "Raichu", "ability": "Hidden Ability"}]}]`,
+			want: []string{`[error] data/dens.json: den 1 (shield) Raichu: pokemon does not exist in pokemon.json`},
+		},
+		{
+			name: "unknown ability",
+			data: `[{"den": "1", "sword": [{"name": "Pikachu", "ability": "Static"}]}]`,
+			want: []string{
+				`[error] data/dens.json: den 1 (sword) Pikachu: unknown ability "Static", must be one of Standard, Hidden Ability, Hidden Possible`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dens := make([]*Den, 0)
+			raw := decodeRecords(t, tt.data, &dens)
+
+			v := newTestValidator()
+			v.validateDens(raw, dens)
+			if got := problemStrings(v.problems); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateDens() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePokemon(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: `[{"dexId": 25, "name": "Pikachu", "type1": "Electric", "type2": "",
+				"abilities": {"ability1": "Static", "ability2": "", "abilityH": "Lightning Rod"},
+				"dens": {"sword": ["1"], "shield": []}}]`,
+			want: []string{},
+		},
+		{
+			name: "unknown dens",
+			data: `[{"dexId": 25, "name": "Pikachu", "type1": "Electric",
+				"abilities": {"ability1": "Static"}, "dens": {"sword": ["2"], "shield": ["3"]}}]`,
+			want: []string{
+				`[error] data/pokemon.json: #25 Pikachu: sword den 2 does not exist in dens.json`,
+				`[error] data/pokemon.json: #25 Pikachu: shield den 3 does not exist in dens.json`,
+			},
+		},
+		{
+			name: "unknown types",
+			data: `[{"dexId": 133, "name": "Eevee", "type1": "Awesome", "type2": "Cute",
+				"abilities": {"ability1": "Run Away"}}]`,
+			want: []string{
+				`[error] data/pokemon.json: #133 Eevee: type1 "Awesome" does not exist in types.json`,
+				`[error] data/pokemon.json: #133 Eevee: type2 "Cute" does not exist in types.json`,
+			},
+		},
+		{
+			name: "missing ability1 and null ability2",
+			data: `[{"dexId": 133, "name": "Eevee", "type1": "Normal", "abilities": {"ability1": "", "ability2": null}}]`,
+			want: []string{
+				`[error] data/pokemon.json: #133 Eevee: ability1 is required`,
+				`[warning] data/pokemon.json: #133 Eevee: ability2 is null, use an empty string instead`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pokemon := make([]*Pokemon, 0)
+			raw := decodeRecords(t, tt.data, &pokemon)
+
+			v := newTestValidator()
+			v.validatePokemon(raw, pokemon)
+			if got := problemStrings(v.problems); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validatePokemon() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBalls(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"valid", `[{"name": "Poke Ball", "modifier": 1}]`, []string{}},
+		{
+			"missing modifier",
+			`[{"name": "Dream Ball"}]`,
+			[]string{`[error] data/balls.json: Dream Ball: modifier is missing`},
+		},
+		{
+			"zero modifier",
+			`[{"name": "Beast Ball", "modifier": 0}]`,
+			[]string{`[error] data/balls.json: Beast Ball: modifier must be greater than 0, got 0`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestValidator()
+			v.validateBalls(decodeRecords(t, tt.data, nil))
+			if got := problemStrings(v.problems); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateBalls() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	types := []*PokemonType{
+		{
+			Name:      "Electric",
+			Offensive: map[string]float64{"Water": 2, "Normal": 1},
+			Defensive: map[string]float64{"Electric": 0.5},
+		},
+	}
+	want := []string{`[error] data/types.json: Electric: offensive type "Water" does not exist`}
+
+	v := newTestValidator()
+	v.validateTypes(types)
+	if got := problemStrings(v.problems); !reflect.DeepEqual(got, want) {
+		t.Errorf("validateTypes() = %q, want %q", got, want)
+	}
+}